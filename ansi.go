@@ -0,0 +1,278 @@
+package helpers
+
+import (
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const ErrInvalidANSISequence = StringError("Invalid ANSI escape sequence")
+
+// ansiBasicColors RGB code of the 16 standard terminal colors(xterm defaults)
+var ansiBasicColors = [16]RGBCode{
+	0x000000, 0xCD0000, 0x00CD00, 0xCDCD00, 0x0000EE, 0xCD00CD, 0x00CDCD, 0xE5E5E5,
+	0x7F7F7F, 0xFF0000, 0x00FF00, 0xFFFF00, 0x5C5CFF, 0xFF00FF, 0x00FFFF, 0xFFFFFF,
+}
+
+// ansiCubeLevels channel values of the 6x6x6 color cube of the xterm 256 color palette
+var ansiCubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ANSIPaletteColor Return RGB code of color `index` of the xterm 256 color palette
+func ANSIPaletteColor(index uint8) RGBCode {
+	if index < 16 {
+		return ansiBasicColors[index]
+	}
+	if index < 232 {
+		index -= 16
+		r := ansiCubeLevels[index/36]
+		g := ansiCubeLevels[(index/6)%6]
+		b := ansiCubeLevels[index%6]
+		return RGB(r, g, b)
+	}
+	gray := 8 + 10*(index-232)
+	return RGB(gray, gray, gray)
+}
+
+func parseSGRParams(params string) ([]int, error) {
+	if params == "" {
+		return []int{0}, nil
+	}
+
+	parts := strings.Split(params, ";")
+	result := make([]int, len(parts))
+	for i := 0; i < len(parts); i++ {
+		if parts[i] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return nil, ErrInvalidANSISequence
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// readExtendedColor read color of a `38`/`48` SGR parameter, return the color and number of consumed parameters
+func readExtendedColor(params []int) (Color, int) {
+	if len(params) >= 2 && params[0] == 5 {
+		if params[1] > 255 {
+			return nil, 2
+		}
+		return RGBColor(ANSIPaletteColor(uint8(params[1]))), 2
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		if params[1] > 255 || params[2] > 255 || params[3] > 255 {
+			return nil, 4
+		}
+		return RGBColor(RGB(uint8(params[1]), uint8(params[2]), uint8(params[3]))), 4
+	}
+	return nil, len(params)
+}
+
+// applySGR apply SGR parameters to the current foreground and background colors and text style, `reset` is
+// true if parameters contain a reset(`0`)
+func applySGR(params []int, fg, bg Color, style TextStyle) (Color, Color, TextStyle, bool) {
+	reset := false
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		switch {
+		case p == 0:
+			fg, bg, style = NoColor, NoColor, NoStyle
+			reset = true
+		case p == 1:
+			style |= Bold
+		case p == 3:
+			style |= Italic
+		case p == 4:
+			style |= Underline
+		case p == 9:
+			style |= Strike
+		case p == 22:
+			style &^= Bold
+		case p == 23:
+			style &^= Italic
+		case p == 24:
+			style &^= Underline
+		case p == 29:
+			style &^= Strike
+		case p >= 30 && p <= 37:
+			fg = RGBColor(ansiBasicColors[p-30])
+		case p >= 90 && p <= 97:
+			fg = RGBColor(ansiBasicColors[p-90+8])
+		case p == 39:
+			fg = NoColor
+		case p >= 40 && p <= 47:
+			bg = RGBColor(ansiBasicColors[p-40])
+		case p >= 100 && p <= 107:
+			bg = RGBColor(ansiBasicColors[p-100+8])
+		case p == 49:
+			bg = NoColor
+		case p == 38 || p == 48:
+			color, consumed := readExtendedColor(params[i+1:])
+			i += consumed
+			if color != nil {
+				if p == 38 {
+					fg = color
+				} else {
+					bg = color
+				}
+			}
+		default:
+			// unsupported attribute
+		}
+	}
+	return fg, bg, style, reset
+}
+
+// ParseSGR Interpret parameters of a single SGR sequence(e.g. `[1, 38, 2, 255, 0, 0]` for `ESC[1;38;2;255;0;0m`)
+// as a color and a text style, `reset` is true if the sequence reset previous attributes(`0` or an empty list),
+// in that case returned color and style are the attributes that are set after the reset. Previous attributes are
+// not known here, so partial resets(e.g. `39` or `22`) have no effect, use `ApplySGR` to apply them
+func ParseSGR(params []int) (color Color, style TextStyle, reset bool) {
+	return ApplySGR(params, NoColor, NoStyle)
+}
+
+// ApplySGR Apply parameters of a single SGR sequence to the current `color` and `style` and return the resulting
+// attributes, so partial resets clear the matching attribute of the current state(e.g. `39` the foreground, `49`
+// the background and `22` the bold). `reset` is true if the sequence contain a full reset(`0` or an empty list)
+func ApplySGR(params []int, color Color, style TextStyle) (Color, TextStyle, bool) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+
+	fg, bg := separateColors(color)
+	fg, bg, style, reset := applySGR(params, fg, bg, style)
+	return combineColors(fg, bg), style, reset
+}
+
+// separateColors Return foreground and background of `color` as separate colors
+func separateColors(color Color) (fg, bg Color) {
+	if color == nil {
+		return NoColor, NoColor
+	}
+	switch color.Coverage() {
+	case Foreground:
+		return color, NoColor
+	case Background:
+		return NoColor, color
+	case Both:
+		return color.AsForeground(), color.AsBackground()
+	default:
+		return NoColor, NoColor
+	}
+}
+
+func combineColors(fg, bg Color) Color {
+	hasFg := fg.Coverage() != NoCoverage
+	hasBg := bg.Coverage() != NoCoverage
+	switch {
+	case hasFg && hasBg:
+		return MixColors(fg, bg)
+	case hasFg:
+		return fg.AsForeground()
+	case hasBg:
+		return bg.AsBackground()
+	default:
+		return NoColor
+	}
+}
+
+// ParseANSI Parse a terminal colored string into a list of colored values.
+// Escape sequences other than SGR(color and style) sequences are ignored
+func ParseANSI(s string) ([]ColoredValue, error) {
+	var fg, bg Color = NoColor, NoColor
+	style := NoStyle
+	result := []ColoredValue{}
+	flush := func(text string) {
+		if text != "" {
+			result = append(result, ColoredValue{Color: combineColors(fg, bg), Style: style, Content: text})
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(s); {
+		if s[i] != '\033' {
+			i++
+			continue
+		}
+
+		flush(s[start:i])
+		if i+1 >= len(s) {
+			return nil, ErrInvalidANSISequence
+		}
+		if s[i+1] != '[' {
+			// a two character escape sequence
+			i += 2
+			start = i
+			continue
+		}
+
+		end := i + 2
+		for end < len(s) && (s[end] < 0x40 || s[end] > 0x7E) {
+			end++
+		}
+		if end >= len(s) {
+			return nil, ErrInvalidANSISequence
+		}
+		if s[end] == 'm' {
+			params, err := parseSGRParams(s[i+2 : end])
+			if err != nil {
+				return nil, err
+			}
+			fg, bg, style, _ = applySGR(params, fg, bg, style)
+		}
+		i = end + 1
+		start = i
+	}
+	flush(s[start:])
+	return result, nil
+}
+
+// ANSIToHTML Convert a terminal colored string into HTML spans
+func ANSIToHTML(s string) (string, error) {
+	values, err := ParseANSI(s)
+	if err != nil {
+		return "", err
+	}
+
+	builder := &strings.Builder{}
+	w := NewColoredWriter(HTML, builder)
+	for i := 0; i < len(values); i++ {
+		values[i].Content = html.EscapeString(values[i].Content.(string))
+		if err = w.WriteContent(values[i]); err != nil {
+			return "", err
+		}
+	}
+	return builder.String(), nil
+}
+
+//region Cursor and screen control
+
+// writeControlSequence write a CSI sequence to `w` if `w` is a terminal(see `GetDefaultContext`), so
+// redirected output never contains control sequences
+func writeControlSequence(w io.Writer, sequence string) error {
+	if GetDefaultContext(w) == MonoColor {
+		return nil
+	}
+	_, err := io.WriteString(w, "\033["+sequence)
+	return err
+}
+
+// MoveCursor Move the cursor to `row` and `col`, both are 1-based
+func MoveCursor(w io.Writer, row, col int) error {
+	if row < 1 || col < 1 {
+		panic("Invalid argument")
+	}
+	return writeControlSequence(w, strconv.Itoa(row)+";"+strconv.Itoa(col)+"H")
+}
+
+func ClearScreen(w io.Writer) error   { return writeControlSequence(w, "2J") }
+func ClearLine(w io.Writer) error     { return writeControlSequence(w, "2K") }
+func HideCursor(w io.Writer) error    { return writeControlSequence(w, "?25l") }
+func ShowCursor(w io.Writer) error    { return writeControlSequence(w, "?25h") }
+func SaveCursor(w io.Writer) error    { return writeControlSequence(w, "s") }
+func RestoreCursor(w io.Writer) error { return writeControlSequence(w, "u") }
+
+//endregion
@@ -0,0 +1,181 @@
+package helpers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseANSI(t *testing.T) {
+	values, err := ParseANSI("a\033[31mb\033[44mc\033[39md\033[0me\033[2Kf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		color   Color
+		content string
+	}{
+		{NoColor, "a"},
+		{RGBColor(0xCD0000), "b"},
+		{MixColors(RGBColor(0xCD0000), RGBColor(0x0000EE)), "c"},
+		{RGBColor(0x0000EE).AsBackground(), "d"},
+		// non-SGR sequences are dropped
+		{NoColor, "e"},
+		{NoColor, "f"},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values but received %d", len(expected), len(values))
+	}
+	for i := 0; i < len(expected); i++ {
+		if values[i].Content != expected[i].content || !ColorsEqual(values[i].Color, expected[i].color) {
+			t.Errorf("value %d: expected %q but received %q", i, expected[i].content, values[i].Content)
+		}
+	}
+}
+
+func TestParseANSIInvalidSequence(t *testing.T) {
+	invalid := []string{"a\033", "a\033[31", "\033[3:1m"}
+	for i := 0; i < len(invalid); i++ {
+		if _, err := ParseANSI(invalid[i]); err != ErrInvalidANSISequence {
+			t.Errorf("%q: expected %v but received %v", invalid[i], ErrInvalidANSISequence, err)
+		}
+	}
+}
+
+func TestANSIToHTML(t *testing.T) {
+	s, err := ANSIToHTML("plain \033[31mred <b>\033[0m & \033[38;2;0;128;0;44mgreen\033[m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `plain <span style="color: #CD0000;">red &lt;b&gt;</span> &amp; ` +
+		`<span style="color: Green;background-color: #0000EE;">green</span>`
+	if s != expected {
+		t.Errorf("expected %q but received %q", expected, s)
+	}
+}
+
+func TestCursorControl(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "truecolor")
+
+	cases := []struct {
+		write    func(w *fakeTerminalWriter) error
+		expected string
+	}{
+		{func(w *fakeTerminalWriter) error { return MoveCursor(w, 3, 14) }, "\033[3;14H"},
+		{func(w *fakeTerminalWriter) error { return ClearScreen(w) }, "\033[2J"},
+		{func(w *fakeTerminalWriter) error { return ClearLine(w) }, "\033[2K"},
+		{func(w *fakeTerminalWriter) error { return HideCursor(w) }, "\033[?25l"},
+		{func(w *fakeTerminalWriter) error { return ShowCursor(w) }, "\033[?25h"},
+		{func(w *fakeTerminalWriter) error { return SaveCursor(w) }, "\033[s"},
+		{func(w *fakeTerminalWriter) error { return RestoreCursor(w) }, "\033[u"},
+	}
+	for i := 0; i < len(cases); i++ {
+		w := &fakeTerminalWriter{isTerminal: true}
+		if err := cases[i].write(w); err != nil {
+			t.Fatal(err)
+		}
+		if w.String() != cases[i].expected {
+			t.Errorf("expected %q but received %q", cases[i].expected, w.String())
+		}
+
+		// control sequences must not be written to a redirected output
+		w = &fakeTerminalWriter{isTerminal: false}
+		if err := cases[i].write(w); err != nil {
+			t.Fatal(err)
+		}
+		if w.Len() != 0 {
+			t.Errorf("expected no output but received %q", w.String())
+		}
+	}
+}
+
+func TestCursorControlOnBasicTerminal(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "")
+	t.Setenv("WT_SESSION", "")
+	t.Setenv("TERM", "xterm")
+
+	w := &fakeTerminalWriter{isTerminal: true}
+	if err := ClearLine(w); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != "\033[2K" {
+		t.Errorf("expected %q but received %q", "\033[2K", w.String())
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := ClearLine(buffer); err != nil || buffer.Len() != 0 {
+		t.Errorf("expected no output but received %q(%v)", buffer.String(), err)
+	}
+}
+
+func TestMoveCursorInvalidArgument(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MoveCursor to panic for a 0 row")
+		}
+	}()
+	MoveCursor(&bytes.Buffer{}, 0, 1)
+}
+
+func TestParseSGR(t *testing.T) {
+	cases := []struct {
+		params []int
+		color  Color
+		style  TextStyle
+		reset  bool
+	}{
+		{[]int{1, 38, 2, 255, 0, 0}, RGBColor(0xFF0000), Bold, false},
+		{[]int{38, 5, 196}, RGBColor(0xFF0000), NoStyle, false},
+		{[]int{48, 5, 21}, RGBColor(0x0000FF).AsBackground(), NoStyle, false},
+		{[]int{91}, RGBColor(0xFF0000), NoStyle, false},
+		{[]int{31, 104}, MixColors(RGBColor(0xCD0000), RGBColor(0x5C5CFF)), NoStyle, false},
+		{[]int{1, 4}, NoColor, Bold | Underline, false},
+		{[]int{3, 9}, NoColor, Italic | Strike, false},
+		{[]int{0}, NoColor, NoStyle, true},
+		{[]int{}, NoColor, NoStyle, true},
+		{nil, NoColor, NoStyle, true},
+		{[]int{0, 1, 32}, RGBColor(0x00CD00), Bold, true},
+		// partial resets have no effect without a previous state
+		{[]int{39, 49, 22}, NoColor, NoStyle, false},
+	}
+	for i := 0; i < len(cases); i++ {
+		color, style, reset := ParseSGR(cases[i].params)
+		if !ColorsEqual(color, cases[i].color) || style != cases[i].style || reset != cases[i].reset {
+			t.Errorf("%v: expected %v %v %v but received %v %v %v", cases[i].params,
+				cases[i].color.HtmlColorName(), cases[i].style, cases[i].reset, color.HtmlColorName(), style, reset)
+		}
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	cases := []struct {
+		params        []int
+		color         Color
+		style         TextStyle
+		expectedColor Color
+		expectedStyle TextStyle
+		reset         bool
+	}{
+		{[]int{39}, MixColors(Red, Blue), Bold, Blue.AsBackground(), Bold, false},
+		{[]int{49}, MixColors(Red, Blue), NoStyle, Red, NoStyle, false},
+		{[]int{39, 49}, MixColors(Red, Blue), NoStyle, NoColor, NoStyle, false},
+		{[]int{22}, Red, Bold | Underline, Red, Underline, false},
+		{[]int{23, 24, 29}, NoColor, Italic | Underline | Strike | Bold, NoColor, Bold, false},
+		{[]int{34}, Red.AsBackground(), NoStyle, MixColors(RGBColor(0x0000EE), Red), NoStyle, false},
+		{[]int{}, Red, Bold, NoColor, NoStyle, true},
+		{[]int{0, 4}, Red, Bold, NoColor, Underline, true},
+		{[]int{1}, nil, NoStyle, NoColor, Bold, false},
+	}
+	for i := 0; i < len(cases); i++ {
+		c := cases[i]
+		color, style, reset := ApplySGR(c.params, c.color, c.style)
+		if !ColorsEqual(color, c.expectedColor) || style != c.expectedStyle || reset != c.reset {
+			t.Errorf("%v: expected %v %v %v but received %v %v %v", c.params,
+				c.expectedColor.HtmlColorName(), c.expectedStyle, c.reset, color.HtmlColorName(), style, reset)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"reflect"
+	"sort"
 )
 
 func SearchInArray(array interface{}, predicate func(interface{}) bool) int {
@@ -100,3 +101,102 @@ func FilterArrayI(array interface{}, predicate func(int) bool) interface{} {
 		panic("This function should only called for slices or arrays")
 	}
 }
+
+// SearchInMap find an entry of a map that satisfy the predicate and return its key.
+// If multiple entries satisfy the predicate, any of them may be returned
+func SearchInMap(m interface{}, predicate func(key, value interface{}) bool) (key interface{}, found bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	value := reflect.ValueOf(m)
+	switch value.Kind() {
+	case reflect.Map:
+		if value.IsNil() {
+			return nil, false
+		}
+
+		iter := value.MapRange()
+		for iter.Next() {
+			k := iter.Key().Interface()
+			if predicate(k, iter.Value().Interface()) {
+				return k, true
+			}
+		}
+		return nil, false
+
+	default:
+		panic("This function should only called for maps")
+	}
+}
+
+// FilterMap create a map of the same type as `m` that only contains entries that satisfy the predicate
+func FilterMap(m interface{}, predicate func(key, value interface{}) bool) interface{} {
+	value := reflect.ValueOf(m)
+	switch value.Kind() {
+	case reflect.Map:
+		if value.IsNil() {
+			return m
+		}
+
+		result := reflect.MakeMap(value.Type())
+		iter := value.MapRange()
+		for iter.Next() {
+			if predicate(iter.Key().Interface(), iter.Value().Interface()) {
+				result.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return result.Interface()
+
+	case reflect.Invalid:
+		// untyped nil
+		return nil
+
+	default:
+		panic("This function should only called for maps")
+	}
+}
+
+// Partition split `in` into items that satisfy the predicate and items that does not, keeping their order
+func Partition[T any](in []T, pred func(T) bool) (matched, unmatched []T) {
+	for i := 0; i < len(in); i++ {
+		if pred(in[i]) {
+			matched = append(matched, in[i])
+		} else {
+			unmatched = append(unmatched, in[i])
+		}
+	}
+	return matched, unmatched
+}
+
+// Counts return number of occurrences of each distinct value of `in`
+func Counts[T comparable](in []T) map[T]int {
+	result := make(map[T]int)
+	for i := 0; i < len(in); i++ {
+		result[in[i]]++
+	}
+	return result
+}
+
+// MostCommon return up to `n` most frequent values of `in`, values with the same frequency are ordered by
+// their first occurrence
+func MostCommon[T comparable](in []T, n int) []T {
+	counts := Counts(in)
+	distinct := make([]T, 0, len(counts))
+	seen := make(map[T]struct{}, len(counts))
+	for i := 0; i < len(in); i++ {
+		if _, ok := seen[in[i]]; !ok {
+			seen[in[i]] = struct{}{}
+			distinct = append(distinct, in[i])
+		}
+	}
+
+	sort.SliceStable(distinct, func(i, j int) bool { return counts[distinct[i]] > counts[distinct[j]] })
+	if n < len(distinct) {
+		if n < 0 {
+			n = 0
+		}
+		distinct = distinct[:n]
+	}
+	return distinct
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchInMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	key, found := SearchInMap(m, func(key, value interface{}) bool { return value.(int) == 2 })
+	if !found || key != "b" {
+		t.Errorf("expected b but received %v(%v)", key, found)
+	}
+	key, found = SearchInMap(m, func(key, value interface{}) bool { return value.(int) > 3 })
+	if found || key != nil {
+		t.Errorf("expected no match but received %v", key)
+	}
+
+	var nilMap map[string]int
+	never := func(key, value interface{}) bool {
+		t.Errorf("expected predicate not to be called for a nil map")
+		return true
+	}
+	if _, found = SearchInMap(nilMap, never); found {
+		t.Errorf("expected no match in a nil map")
+	}
+	if _, found = SearchInMap(nil, never); found {
+		t.Errorf("expected no match in nil")
+	}
+}
+
+func TestFilterMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	even := FilterMap(m, func(key, value interface{}) bool { return value.(int)%2 == 0 }).(map[string]int)
+	if len(even) != 2 || even["b"] != 2 || even["d"] != 4 {
+		t.Errorf("expected map[b:2 d:4] but received %v", even)
+	}
+	if len(m) != 4 {
+		t.Errorf("expected source map to be unchanged")
+	}
+
+	var nilMap map[string]int
+	never := func(key, value interface{}) bool {
+		t.Errorf("expected predicate not to be called for a nil map")
+		return true
+	}
+	if result := FilterMap(nilMap, never).(map[string]int); result != nil {
+		t.Errorf("expected a nil map but received %v", result)
+	}
+	if result := FilterMap(nil, never); result != nil {
+		t.Errorf("expected nil but received %v", result)
+	}
+}
+
+func TestMapFunctionsPanicForNonMaps(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a slice")
+		}
+	}()
+	FilterMap([]int{1}, func(key, value interface{}) bool { return true })
+}
+
+func TestPartition(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6, 7}
+	even, odd := Partition(in, func(n int) bool { return n%2 == 0 })
+	if !reflect.DeepEqual(even, []int{2, 4, 6}) {
+		t.Errorf("expected [2 4 6] but received %v", even)
+	}
+	if !reflect.DeepEqual(odd, []int{1, 3, 5, 7}) {
+		t.Errorf("expected [1 3 5 7] but received %v", odd)
+	}
+	if len(even)+len(odd) != len(in) {
+		t.Errorf("expected %d items but received %d", len(in), len(even)+len(odd))
+	}
+
+	matched, unmatched := Partition([]string{}, func(string) bool { return true })
+	if len(matched) != 0 || len(unmatched) != 0 {
+		t.Errorf("expected empty partitions but received %v, %v", matched, unmatched)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	counts := Counts([]string{"a", "b", "a", "c", "a", "b"})
+	if !reflect.DeepEqual(counts, map[string]int{"a": 3, "b": 2, "c": 1}) {
+		t.Errorf("unexpected counts %v", counts)
+	}
+	if counts = Counts([]string(nil)); len(counts) != 0 {
+		t.Errorf("expected no count but received %v", counts)
+	}
+}
+
+func TestMostCommon(t *testing.T) {
+	in := []string{"x", "b", "a", "b", "a", "c", "a", "c"}
+	cases := []struct {
+		n        int
+		expected []string
+	}{
+		{1, []string{"a"}},
+		// b and c are tied, b occurred first
+		{3, []string{"a", "b", "c"}},
+		{10, []string{"a", "b", "c", "x"}},
+		{0, []string{}},
+		{-1, []string{}},
+	}
+	for i := 0; i < len(cases); i++ {
+		if result := MostCommon(in, cases[i].n); !reflect.DeepEqual(result, cases[i].expected) {
+			t.Errorf("%d: expected %v but received %v", cases[i].n, cases[i].expected, result)
+		}
+	}
+}
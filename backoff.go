@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// JitterMode describe how a random jitter will be applied to backoff intervals
+type JitterMode int
+
+const (
+	// NoJitter use backoff intervals as is
+	NoJitter JitterMode = iota
+	// FullJitter use a random interval in [0, interval)
+	FullJitter
+	// EqualJitter use a random interval in [interval/2, interval)
+	EqualJitter
+)
+
+// BackoffPolicy describe how long we should wait between retries of a failed operation
+type BackoffPolicy struct {
+	// InitialInterval wait time before the first retry
+	InitialInterval time.Duration
+	// MaxInterval maximum wait time between retries, 0 means no limit
+	MaxInterval time.Duration
+	// Multiplier factor that each interval will be multiplied with to calculate next interval
+	Multiplier float64
+	// MaxRetries maximum number of retries, negative value means retry forever
+	MaxRetries int
+	// Jitter random jitter that will be applied to the intervals, so simultaneous retries will be spread
+	Jitter JitterMode
+	// Random source of randomness for the jitter that return a value in [0, 1), `rand.Float64` if it is `nil`
+	Random func() float64
+	// After time source that is used for waiting, `time.After` if it is `nil`
+	After func(d time.Duration) <-chan time.Time
+}
+
+var DefaultBackoffPolicy = BackoffPolicy{
+	InitialInterval: 100 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	MaxRetries:      3,
+}
+
+// CanRetry Check if `attempt`(0 based) retry is allowed by this policy
+func (this BackoffPolicy) CanRetry(attempt int) bool {
+	return this.MaxRetries < 0 || attempt < this.MaxRetries
+}
+
+// Interval Calculate wait time before `attempt`(0 based) retry
+func (this BackoffPolicy) Interval(attempt int) time.Duration {
+	interval := float64(this.InitialInterval)
+	multiplier := this.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 0; i < attempt; i++ {
+		interval *= multiplier
+		if this.MaxInterval > 0 && interval >= float64(this.MaxInterval) {
+			return this.MaxInterval
+		}
+	}
+	if this.MaxInterval > 0 && interval > float64(this.MaxInterval) {
+		return this.MaxInterval
+	}
+	return time.Duration(interval)
+}
+
+// JitteredInterval Calculate wait time before `attempt`(0 based) retry and apply the jitter to it
+func (this BackoffPolicy) JitteredInterval(attempt int) time.Duration {
+	interval := this.Interval(attempt)
+	random := this.Random
+	if random == nil {
+		random = rand.Float64
+	}
+
+	switch this.Jitter {
+	case FullJitter:
+		return time.Duration(random() * float64(interval))
+	case EqualJitter:
+		half := interval / 2
+		return half + time.Duration(random()*float64(interval-half))
+	default:
+		return interval
+	}
+}
+
+// Wait Wait before `attempt`(0 based) retry, it will return `ctx.Err()` if `ctx` is done before end of the wait
+func (this BackoffPolicy) Wait(ctx context.Context, attempt int) error {
+	return sleepContext(ctx, this.JitteredInterval(attempt), this.After)
+}
+
+// sleepContext Wait for `d` using `after`(or a timer if it is `nil`) and return `ctx.Err()` if `ctx` is done sooner
+func sleepContext(ctx context.Context, d time.Duration, after func(d time.Duration) <-chan time.Time) error {
+	var elapsed <-chan time.Time
+	if after != nil {
+		elapsed = after(d)
+	} else {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		elapsed = timer.C
+	}
+
+	select {
+	case <-elapsed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BackoffDuration Calculate `min(base * 2^attempt, max)`, `max <= 0` means no limit
+func BackoffDuration(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	const maxDuration = time.Duration(1<<63 - 1)
+	result := base
+	for i := 0; i < attempt; i++ {
+		if max > 0 && result >= max {
+			break
+		}
+		if result > maxDuration/2 {
+			// doubling would overflow
+			result = maxDuration
+			break
+		}
+		result *= 2
+	}
+	if max > 0 && result > max {
+		return max
+	}
+	return result
+}
+
+// BackoffSleep Sleep for `BackoffDuration(attempt, base, max)`, it return `ctx.Err()` as soon as `ctx` is done
+func BackoffSleep(ctx context.Context, attempt int, base, max time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return sleepContext(ctx, BackoffDuration(attempt, base, max), nil)
+}
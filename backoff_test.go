@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoffPolicyInterval(t *testing.T) {
+	policy := BackoffPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i := 0; i < len(expected); i++ {
+		if d := policy.Interval(i); d != expected[i]*time.Millisecond {
+			t.Errorf("attempt %d: expected %v but received %v", i, expected[i]*time.Millisecond, d)
+		}
+	}
+}
+
+func TestBackoffPolicyJitter(t *testing.T) {
+	randoms := []float64{0, 0.5, 0.999}
+	for i := 0; i < len(randoms); i++ {
+		r := randoms[i]
+		policy := BackoffPolicy{InitialInterval: 100 * time.Millisecond, Multiplier: 2, Random: func() float64 { return r }}
+		for attempt := 0; attempt < 4; attempt++ {
+			interval := policy.Interval(attempt)
+
+			policy.Jitter = NoJitter
+			if d := policy.JitteredInterval(attempt); d != interval {
+				t.Errorf("no jitter: expected %v but received %v", interval, d)
+			}
+
+			policy.Jitter = FullJitter
+			if d := policy.JitteredInterval(attempt); d < 0 || d >= interval {
+				t.Errorf("full jitter(%v): expected %v in [0, %v)", r, d, interval)
+			} else if d != time.Duration(r*float64(interval)) {
+				t.Errorf("full jitter(%v): expected %v but received %v", r, time.Duration(r*float64(interval)), d)
+			}
+
+			policy.Jitter = EqualJitter
+			if d := policy.JitteredInterval(attempt); d < interval/2 || d >= interval {
+				t.Errorf("equal jitter(%v): expected %v in [%v, %v)", r, d, interval/2, interval)
+			}
+		}
+	}
+}
+
+func TestBackoffPolicyCanRetry(t *testing.T) {
+	policy := BackoffPolicy{MaxRetries: 2}
+	if !policy.CanRetry(0) || !policy.CanRetry(1) || policy.CanRetry(2) {
+		t.Errorf("expected exactly 2 retries to be allowed")
+	}
+	policy.MaxRetries = -1
+	if !policy.CanRetry(1000) {
+		t.Errorf("expected a negative MaxRetries to retry forever")
+	}
+}
+
+func TestBackoffPolicyWaitUseTimeSource(t *testing.T) {
+	var waited []time.Duration
+	policy := BackoffPolicy{
+		InitialInterval: time.Hour,
+		Multiplier:      2,
+		After: func(d time.Duration) <-chan time.Time {
+			waited = append(waited, d)
+			ch := make(chan time.Time, 1)
+			ch <- time.Time{}
+			return ch
+		},
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := policy.Wait(context.Background(), attempt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(waited) != 2 || waited[0] != time.Hour || waited[1] != 2*time.Hour {
+		t.Errorf("expected [1h 2h] but received %v", waited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	policy.After = nil
+	if err := policy.Wait(ctx, 0); err != context.Canceled {
+		t.Errorf("expected %v but received %v", context.Canceled, err)
+	}
+}
+
+func TestBackoffDuration(t *testing.T) {
+	cases := []struct {
+		attempt   int
+		base, max time.Duration
+		expected  time.Duration
+	}{
+		{0, time.Second, time.Minute, time.Second},
+		{1, time.Second, time.Minute, 2 * time.Second},
+		{5, time.Second, time.Minute, 32 * time.Second},
+		{6, time.Second, time.Minute, time.Minute},
+		{1000, time.Second, time.Minute, time.Minute},
+		{3, time.Second, 0, 8 * time.Second},
+		{1000, time.Second, 0, time.Duration(1<<63 - 1)},
+		{3, 0, time.Minute, 0},
+	}
+	for i := 0; i < len(cases); i++ {
+		c := cases[i]
+		if d := BackoffDuration(c.attempt, c.base, c.max); d != c.expected {
+			t.Errorf("BackoffDuration(%d, %v, %v): expected %v but received %v", c.attempt, c.base, c.max, c.expected, d)
+		}
+	}
+}
+
+func TestBackoffSleep(t *testing.T) {
+	start := time.Now()
+	if err := BackoffSleep(context.Background(), 2, time.Millisecond, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 4*time.Millisecond {
+		t.Errorf("expected to sleep at least 4ms but slept %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := BackoffSleep(ctx, 0, time.Hour, 0); err != context.DeadlineExceeded {
+		t.Errorf("expected %v but received %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancellation to return promptly but it took %v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := BackoffSleep(canceled, 0, time.Hour, 0); err != context.Canceled {
+		t.Errorf("expected %v but received %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/x509"
+	"net"
+	"time"
+)
+
+// CertBuilder a fluent builder for `x509.Certificate`
+type CertBuilder struct {
+	options     X509CertificateOptions
+	dnsNames    []string
+	ipAddresses []net.IP
+	keyUsage    x509.KeyUsage
+	extKeyUsage []x509.ExtKeyUsage
+}
+
+// NewCertBuilder Create a `CertBuilder` for a certificate that is valid for one year
+func NewCertBuilder() *CertBuilder {
+	return &CertBuilder{
+		options: X509CertificateOptions{ValidFor: 365 * 24 * time.Hour},
+	}
+}
+
+func (this *CertBuilder) CommonName(name string) *CertBuilder {
+	this.options.Subject.CommonName = name
+	return this
+}
+func (this *CertBuilder) Organization(organizations ...string) *CertBuilder {
+	this.options.Subject.Organization = append(this.options.Subject.Organization, organizations...)
+	return this
+}
+func (this *CertBuilder) DNSNames(names ...string) *CertBuilder {
+	this.dnsNames = append(this.dnsNames, names...)
+	return this
+}
+func (this *CertBuilder) IPAddresses(ips ...net.IP) *CertBuilder {
+	this.ipAddresses = append(this.ipAddresses, ips...)
+	return this
+}
+func (this *CertBuilder) ValidFor(duration time.Duration) *CertBuilder {
+	this.options.ValidFor = duration
+	this.options.NotAfter = time.Time{}
+	return this
+}
+func (this *CertBuilder) IsCA(isCA bool) *CertBuilder {
+	this.options.IsCA = isCA
+	return this
+}
+
+// KeyUsage Replace default key usage of the certificate
+func (this *CertBuilder) KeyUsage(usage x509.KeyUsage) *CertBuilder {
+	this.keyUsage = usage
+	return this
+}
+
+// ExtKeyUsage Replace default extended key usages of the certificate
+func (this *CertBuilder) ExtKeyUsage(usages ...x509.ExtKeyUsage) *CertBuilder {
+	this.extKeyUsage = usages
+	return this
+}
+
+// Build Create the certificate, it panics if it can't generate a serial number for the certificate
+func (this *CertBuilder) Build() *x509.Certificate {
+	result, err := CreateX509CertificateWithOptions(this.options)
+	if err != nil {
+		panic(err)
+	}
+
+	result.DNSNames = append([]string(nil), this.dnsNames...)
+	result.IPAddresses = append([]net.IP(nil), this.ipAddresses...)
+	if this.keyUsage != 0 {
+		result.KeyUsage = this.keyUsage
+	}
+	if this.extKeyUsage != nil {
+		result.ExtKeyUsage = append([]x509.ExtKeyUsage(nil), this.extKeyUsage...)
+	}
+	return result
+}
+
+// SignWith Build the certificate and sign it with `issuer`, if `issuer` is nil the certificate will be
+// self signed. If `key` is nil a new key will be generated for the certificate
+func (this *CertBuilder) SignWith(issuer *CertAndKey, key crypto.PrivateKey) (*CertAndKey, error) {
+	return CreateCertificate(this.Build(), key, issuer)
+}
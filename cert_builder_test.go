@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCertBuilderSignWith(t *testing.T) {
+	root := newTestCertificate(t, "root", true, nil)
+	key, err := CreatePrivateKey(ECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	leaf, err := NewCertBuilder().
+		CommonName("leaf").
+		Organization("simba").
+		DNSNames("example.com", "www.example.com").
+		IPAddresses(net.ParseIP("127.0.0.1")).
+		ValidFor(time.Hour).
+		KeyUsage(x509.KeyUsageDigitalSignature).
+		ExtKeyUsage(x509.ExtKeyUsageServerAuth).
+		SignWith(root, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := leaf.Certificate
+	if cert.Subject.CommonName != "leaf" {
+		t.Errorf("expected %q but received %q", "leaf", cert.Subject.CommonName)
+	}
+	if len(cert.Subject.Organization) != 1 || cert.Subject.Organization[0] != "simba" {
+		t.Errorf("expected organization %q but received %v", "simba", cert.Subject.Organization)
+	}
+	if len(cert.DNSNames) != 2 || cert.DNSNames[0] != "example.com" || cert.DNSNames[1] != "www.example.com" {
+		t.Errorf("unexpected DNS names %v", cert.DNSNames)
+	}
+	if len(cert.IPAddresses) != 1 || !cert.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("unexpected IP addresses %v", cert.IPAddresses)
+	}
+	if cert.NotAfter.Before(start.Add(59*time.Minute)) || cert.NotAfter.After(start.Add(61*time.Minute)) {
+		t.Errorf("expected certificate to be valid for an hour but it expires at %v", cert.NotAfter)
+	}
+	if cert.IsCA {
+		t.Error("expected a leaf certificate")
+	}
+	if cert.KeyUsage != x509.KeyUsageDigitalSignature {
+		t.Errorf("expected key usage %v but received %v", x509.KeyUsageDigitalSignature, cert.KeyUsage)
+	}
+	if len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageServerAuth {
+		t.Errorf("unexpected ext key usages %v", cert.ExtKeyUsage)
+	}
+	if err = cert.CheckSignatureFrom(root.Certificate); err != nil {
+		t.Errorf("expected certificate to be signed by root: %v", err)
+	}
+}
+
+func TestCertBuilderSelfSigned(t *testing.T) {
+	key, err := CreatePrivateKey(ECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := NewCertBuilder().CommonName("ca").IsCA(true).SignWith(nil, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ca.Certificate.IsCA {
+		t.Error("expected a CA certificate")
+	}
+	if err = ca.Certificate.CheckSignatureFrom(ca.Certificate); err != nil {
+		t.Errorf("expected certificate to be self signed: %v", err)
+	}
+
+	_, err = NewCertBuilder().CommonName("leaf").SignWith(nil, key)
+	if err != NoIssuerCertMustBeCA {
+		t.Errorf("expected %v but received %v", NoIssuerCertMustBeCA, err)
+	}
+}
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"context"
 	"sync"
 )
 
@@ -45,3 +46,35 @@ func SignalWhenAllErr(channels ...<-chan error) <-chan []error {
 	}()
 	return result
 }
+
+// ErrChanToContext create a context that will be cancelled with the error as its cause, when `ch` yield a value.
+// If `ch` closed without yielding any value, the context remain alive until the returned cancel function is called
+func ErrChanToContext(ch <-chan error) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		select {
+		case err, ok := <-ch:
+			if ok {
+				cancel(err)
+			}
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// DrainErrors read one error from each channel that have an available error without blocking and
+// return the non-nil ones
+func DrainErrors(channels ...<-chan error) []error {
+	var result []error
+	for i := 0; i < len(channels); i++ {
+		select {
+		case err := <-channels[i]:
+			if err != nil {
+				result = append(result, err)
+			}
+		default:
+		}
+	}
+	return result
+}
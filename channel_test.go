@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrChanToContextCancelWithCause(t *testing.T) {
+	ch := make(chan error, 1)
+	ctx, cancel := ErrChanToContext(ch)
+	defer cancel(nil)
+
+	failure := errors.New("service failed")
+	ch <- failure
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context is not cancelled after the channel yielded an error")
+	}
+	if cause := context.Cause(ctx); cause != failure {
+		t.Errorf("expected %v as the cause but received %v", failure, cause)
+	}
+}
+
+func TestErrChanToContextClosedChannel(t *testing.T) {
+	ch := make(chan error)
+	ctx, cancel := ErrChanToContext(ch)
+	close(ch)
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("context cancelled after the channel closed without any error")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel(nil)
+	<-ctx.Done()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected context.Canceled but received %v", ctx.Err())
+	}
+}
+
+func TestDrainErrors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	withA := make(chan error, 2)
+	withA <- errA
+	withA <- errors.New("not read")
+	empty := make(chan error, 1)
+	withNil := make(chan error, 1)
+	withNil <- nil
+	closed := make(chan error)
+	close(closed)
+	withB := make(chan error, 1)
+	withB <- errB
+
+	result := DrainErrors(withA, empty, withNil, closed, nil, withB)
+	if len(result) != 2 || result[0] != errA || result[1] != errB {
+		t.Errorf("expected [a b] but received %v", result)
+	}
+	if len(withA) != 1 {
+		t.Errorf("expected a single error to be read from each channel")
+	}
+	if result = DrainErrors(empty); result != nil {
+		t.Errorf("expected nil but received %v", result)
+	}
+}
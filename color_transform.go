@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"math"
+)
+
+// coverageBit return coverage bit of this color, so transforms can keep it
+func (this RGBColor) coverageBit() RGBColor { return this & 0x80000000 }
+
+// Grayscale Return the gray color that has the same luma(Rec. 709 weights) as this color
+func (this RGBColor) Grayscale() RGBColor {
+	code := this.Code()
+	gray := uint8(math.Round(0.2126*float64(code.Red()) + 0.7152*float64(code.Green()) + 0.0722*float64(code.Blue())))
+	return RGBColor(RGB(gray, gray, gray)) | this.coverageBit()
+}
+
+// Invert Return inverse of this color, every channel of it is replaced by `255 - channel`
+func (this RGBColor) Invert() RGBColor {
+	return RGBColor(this.Code().Invert()) | this.coverageBit()
+}
+
+// Darken Mix this color with black by `factor`(clamped to [0, 1]), 0 keep the color and 1 make it black.
+// Mixing is done in RGB space, unlike `RGBCode.Darken` that change lightness in HSL space
+func (this RGBColor) Darken(factor float64) RGBColor { return this.Lerp(RGBColor(0x000000), factor) }
+
+// Lighten Mix this color with white by `factor`(clamped to [0, 1]), 0 keep the color and 1 make it white.
+// Mixing is done in RGB space, unlike `RGBCode.Lighten` that change lightness in HSL space
+func (this RGBColor) Lighten(factor float64) RGBColor { return this.Lerp(RGBColor(0xFFFFFF), factor) }
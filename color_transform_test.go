@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestRGBColorGrayscale(t *testing.T) {
+	tests := []struct {
+		color    RGBColor
+		expected RGBColor
+	}{
+		{0xFFFFFF, 0xFFFFFF},
+		{0x000000, 0x000000},
+		{0xFF0000, 0x363636},
+		{0x00FF00, 0xB6B6B6},
+		{0x0000FF, 0x121212},
+		{0x808080, 0x808080},
+	}
+	for _, test := range tests {
+		if gray := test.color.Grayscale(); gray != test.expected {
+			t.Errorf("Grayscale(%06X): expected %08X but received %08X", uint32(test.color), uint32(test.expected), uint32(gray))
+		}
+	}
+	if gray := RGBColor(0xFF0000).Toggle().Grayscale(); gray != RGBColor(0x363636).Toggle() {
+		t.Errorf("expected Grayscale to keep background coverage but received %08X", uint32(gray))
+	}
+}
+
+func TestRGBColorInvert(t *testing.T) {
+	if color := RGBColor(0x123456).Invert(); color != 0xEDCBA9 {
+		t.Errorf("expected %06X but received %08X", 0xEDCBA9, uint32(color))
+	}
+	if color := RGBColor(0x123456).Invert().Invert(); color != 0x123456 {
+		t.Errorf("expected Invert to be its own inverse but received %08X", uint32(color))
+	}
+	if color := RGBColor(0x000000).Toggle().Invert(); color != RGBColor(0xFFFFFF).Toggle() {
+		t.Errorf("expected Invert to keep background coverage but received %08X", uint32(color))
+	}
+}
+
+func TestRGBColorLightenAndDarken(t *testing.T) {
+	color := RGBColor(0x804020)
+	tests := []struct {
+		name     string
+		result   RGBColor
+		expected RGBColor
+	}{
+		{"Darken(0)", color.Darken(0), 0x804020},
+		{"Darken(0.5)", color.Darken(0.5), 0x402010},
+		{"Darken(1)", color.Darken(1), 0x000000},
+		{"Darken(2)", color.Darken(2), 0x000000},
+		{"Lighten(0)", color.Lighten(0), 0x804020},
+		{"Lighten(0.5)", color.Lighten(0.5), 0xC0A090},
+		{"Lighten(1)", color.Lighten(1), 0xFFFFFF},
+		{"Lighten(-1)", color.Lighten(-1), 0x804020},
+	}
+	for _, test := range tests {
+		if test.result != test.expected {
+			t.Errorf("%s: expected %06X but received %08X", test.name, uint32(test.expected), uint32(test.result))
+		}
+	}
+
+	background := color.Toggle()
+	if darker := background.Darken(0.5); darker != RGBColor(0x402010).Toggle() {
+		t.Errorf("expected Darken to keep background coverage but received %08X", uint32(darker))
+	}
+	if lighter := background.Lighten(1); lighter != RGBColor(0xFFFFFF).Toggle() {
+		t.Errorf("expected Lighten to keep background coverage but received %08X", uint32(lighter))
+	}
+}
+
+func TestRGBColorLightenIsNotHSL(t *testing.T) {
+	// mixing with white in RGB space differ from increasing lightness of the same color
+	if mixed, hsl := RGBColor(0xFF0000).Lighten(0.2), RGBColor(RGBCode(0xFF0000).Lighten(20)); mixed == hsl {
+		t.Errorf("expected RGB and HSL lightening to differ but both are %08X", uint32(mixed))
+	}
+	if color := RGBColor(0xFF0000).Lighten(0.2); color != 0xFF3333 {
+		t.Errorf("expected #FF3333 but received %08X", uint32(color))
+	}
+}
@@ -1,10 +1,14 @@
 package helpers
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"os"
+	"mime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 const (
@@ -14,15 +18,22 @@ const (
 	TTY       TTYContext  = true
 	MonoColor TTYContext  = false
 	HTML      HTMLContext = true
+	// LineTTY a ``TTY`` context that color each line of the content independently
+	LineTTY LineTTYContext = true
+	// Strip a context that write the content without any color or style, see ``StripContext``
+	Strip StripContext = true
 )
 
 //region RGBCode: RGB representation of a color
 type RGBCode uint32
 
+// RGB Create an ``RGBCode`` from its channels
+func RGB(r, g, b uint8) RGBCode { return RGBCode(uint32(r)<<16 | uint32(g)<<8 | uint32(b)) }
+
 func (this RGBCode) Red() uint8     { return uint8((this >> 16) & 0xFF) }
 func (this RGBCode) Green() uint8   { return uint8((this >> 8) & 0xFF) }
 func (this RGBCode) Blue() uint8    { return uint8((this >> 0) & 0xFF) }
-func (this RGBCode) String() string { return fmt.Sprintf("#%X", uint32(this&0xFFFFFF)) }
+func (this RGBCode) String() string { return fmt.Sprintf("#%06X", uint32(this&0xFFFFFF)) }
 func (this RGBCode) ToColor() Color {
 	if this == NoColorCode {
 		return NoColor
@@ -35,6 +46,25 @@ func (this RGBCode) ToColor() Color {
 
 // endregion
 
+//region RGBACode: ``0xRRGGBBAA`` representation of a color and its alpha, high byte of an ``RGBCode`` is reserved
+type RGBACode uint32
+
+// RGBA Create an ``RGBACode`` from its channels and its alpha
+func RGBA(r, g, b, a uint8) RGBACode {
+	return RGBACode(uint32(r)<<24 | uint32(g)<<16 | uint32(b)<<8 | uint32(a))
+}
+
+func (this RGBACode) Red() uint8     { return uint8((this >> 24) & 0xFF) }
+func (this RGBACode) Green() uint8   { return uint8((this >> 16) & 0xFF) }
+func (this RGBACode) Blue() uint8    { return uint8((this >> 8) & 0xFF) }
+func (this RGBACode) Alpha() uint8   { return uint8((this >> 0) & 0xFF) }
+func (this RGBACode) String() string { return fmt.Sprintf("#%08X", uint32(this)) }
+
+// RGB Return color of this code without its alpha
+func (this RGBACode) RGB() RGBCode { return RGB(this.Red(), this.Green(), this.Blue()) }
+
+// endregion
+
 type ColorCoverage int
 
 const (
@@ -60,6 +90,29 @@ type Color interface {
 	TerminalColorName() ColorName
 }
 
+// ColorsEqual Check if two colors are equal, a ``nil`` color is equal to ``NoColor``
+func ColorsEqual(a, b Color) bool {
+	if a == nil {
+		a = NoColor
+	}
+	if b == nil {
+		b = NoColor
+	}
+
+	coverage := a.Coverage()
+	if coverage != b.Coverage() {
+		return false
+	}
+	switch coverage {
+	case NoCoverage:
+		return true
+	case Both:
+		return ColorsEqual(a.AsForeground(), b.AsForeground()) && ColorsEqual(a.AsBackground(), b.AsBackground())
+	default:
+		return a.Code() == b.Code()
+	}
+}
+
 //region NoColorT: Implementation of a nil value for ``Color`` interface
 type NoColorT bool
 
@@ -103,6 +156,19 @@ func (this RGBColor) TerminalColorName() ColorName {
 		return ColorName{Foreground: fmt.Sprintf("38;2;%d;%d;%d", code.Red(), code.Green(), code.Blue())}
 	}
 }
+func (this RGBColor) Equals(other Color) bool { return ColorsEqual(this, other) }
+
+// WithBackground Return a color that use this color as its foreground and ``bg`` as its background
+func (this RGBColor) WithBackground(bg Color) Color { return MixColors(this, bg) }
+
+// WithForeground Return a color that use ``fg`` as its foreground and this color as its background
+func (this RGBColor) WithForeground(fg Color) Color { return MixColors(fg, this) }
+
+func (this RGBColor) IsBackground() bool { return (this & 0x80000000) != 0 }
+func (this RGBColor) IsForeground() bool { return (this & 0x80000000) == 0 }
+
+// Toggle Return this color as a background if it is a foreground and vice versa
+func (this RGBColor) Toggle() RGBColor { return this ^ 0x80000000 }
 
 //endregion
 
@@ -143,6 +209,7 @@ type ColoredWriter struct {
 	context ColorContext
 	w       io.Writer
 	color   Color
+	style   TextStyle
 }
 
 func NewColoredWriterWithColor(context ColorContext, w io.Writer, color Color) *ColoredWriter {
@@ -160,6 +227,18 @@ func (this *ColoredWriter) SetColor(color Color) (oldColor Color) {
 	}
 	return oldColor
 }
+func (this *ColoredWriter) restoreColor(color Color) { this.color = color }
+
+// GetStyle Return the text style that is applied to the content written to this writer
+func (this *ColoredWriter) GetStyle() TextStyle { return this.style }
+
+// SetStyle Add attributes of ``style`` to the current style, so nested styles are combined with the outer styles
+func (this *ColoredWriter) SetStyle(style TextStyle) (oldStyle TextStyle) {
+	oldStyle = this.style
+	this.style |= style
+	return oldStyle
+}
+func (this *ColoredWriter) restoreStyle(style TextStyle) { this.style = style }
 func (this *ColoredWriter) Write(b []byte) error {
 	return this.context.Write(this, b)
 }
@@ -189,16 +268,26 @@ type ColoredContent interface {
 //region ColoredValue: a simple value that bind a content with a ``Color``
 type ColoredValue struct {
 	Color   Color
+	Style   TextStyle
 	Content interface{}
 }
 
 func (this ColoredValue) Render(w *ColoredWriter) error {
 	oldColor := w.SetColor(this.Color)
-	defer w.SetColor(oldColor)
+	// ``SetColor`` ignore ``NoColor``, so we must restore the old color directly
+	defer w.restoreColor(oldColor)
+	oldStyle := w.SetStyle(this.Style)
+	defer w.restoreStyle(oldStyle)
 
 	return w.WriteContent(this.Content)
 }
 
+// WithStyle Return a copy of this value that its content is also rendered with ``style``
+func (this ColoredValue) WithStyle(style TextStyle) ColoredValue {
+	this.Style |= style
+	return this
+}
+
 //endregion
 
 //region FormatContent: A formatter that support ``ColoredContent`` as its argument
@@ -209,19 +298,33 @@ func CreateFormatContent(format string, args ...interface{}) FormatContent {
 	return FormatContent(result)
 }
 func (this FormatContent) Render(w *ColoredWriter) error {
+	return FormatInfo(this).RenderTo(w, nil)
+}
+
+func renderFormatNode(w *ColoredWriter, node FormatNode) error {
+	if node.FormatString == "" {
+		return w.WriteContent(node.Arg)
+	} else if node.NoArg {
+		value := node.Format()
+		return w.WriteContent(value)
+	} else if ccontent, ok := node.Arg.(ColoredContent); ok {
+		return w.WriteContent(ccontent)
+	} else {
+		value := node.Format()
+		return w.WriteString(value)
+	}
+}
+
+// RenderTo render nodes of this format to ``w``, ``resolveColor`` may be used to color each node and it may
+// return ``nil`` or ``NoColor`` to keep current color of the writer
+func (this FormatInfo) RenderTo(w *ColoredWriter, resolveColor func(node FormatNode) Color) error {
 	for i := 0; i < len(this); i++ {
 		var err error
 		node := this[i]
-		if node.FormatString == "" {
-			err = w.WriteContent(node.Arg)
-		} else if node.NoArg {
-			value := node.Format()
-			err = w.WriteContent(value)
-		} else if ccontent, ok := node.Arg.(ColoredContent); ok {
-			err = w.WriteContent(ccontent)
+		if resolveColor != nil {
+			err = w.WriteContent(CContent(resolveColor(node), FormatContent{node}))
 		} else {
-			value := node.Format()
-			err = w.WriteString(value)
+			err = renderFormatNode(w, node)
 		}
 		if err != nil {
 			return err
@@ -261,6 +364,20 @@ func writeTerminalColor(w io.Writer, color string) error {
 	return nil
 }
 
+func writeTerminalColorName(w io.Writer, clr ColorName) error {
+	if clr.Foreground != "" {
+		if err := writeTerminalColor(w, clr.Foreground); err != nil {
+			return err
+		}
+	}
+	if clr.Background != "" {
+		if err := writeTerminalColor(w, clr.Background); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (this TTYContext) Name() string {
 	if this {
 		return "TTY"
@@ -268,22 +385,41 @@ func (this TTYContext) Name() string {
 		return "MonoColor"
 	}
 }
+
+// ColorLevel Return ``TrueColorLevel`` for ``TTY`` and ``NoColorLevel`` for ``MonoColor``
+func (this TTYContext) ColorLevel() ColorLevel {
+	if this {
+		return TrueColorLevel
+	}
+	return NoColorLevel
+}
+
+// TerminalColorName Return terminal name of ``color`` in this context, ``MonoColor`` has no color name
+func (this TTYContext) TerminalColorName(color Color) ColorName {
+	if this {
+		return color.TerminalColorName()
+	}
+	return ColorName{}
+}
 func (this TTYContext) Write(w *ColoredWriter, b []byte) error {
+	if len(b) == 0 {
+		// there is no point in coloring nothing
+		return nil
+	}
+
 	var err error
 	requireReset := false
 	if this {
-		if clr := w.GetColor().TerminalColorName(); !clr.IsEmpty() {
+		if clr := this.TerminalColorName(w.GetColor()); !clr.IsEmpty() {
 			requireReset = true
-
-			if clr.Foreground != "" {
-				if err = writeTerminalColor(w.GetWriter(), clr.Foreground); err != nil {
-					return err
-				}
+			if err = writeTerminalColorName(w.GetWriter(), clr); err != nil {
+				return err
 			}
-			if clr.Background != "" {
-				if err = writeTerminalColor(w.GetWriter(), clr.Background); err != nil {
-					return err
-				}
+		}
+		if style := w.GetStyle(); style != NoStyle {
+			requireReset = true
+			if err = writeTerminalStyle(w.GetWriter(), style); err != nil {
+				return err
 			}
 		}
 	}
@@ -301,6 +437,60 @@ func (this TTYContext) Write(w *ColoredWriter, b []byte) error {
 
 //endregion
 
+//region LineTTYContext: A ``TTY`` context that reapply the color at the start of each line
+type LineTTYContext bool
+
+func (this LineTTYContext) Name() string { return "LineTTY" }
+func (this LineTTYContext) ColorLevel() ColorLevel {
+	return TTYContext(this).ColorLevel()
+}
+func (this LineTTYContext) TerminalColorName(color Color) ColorName {
+	return TTYContext(this).TerminalColorName(color)
+}
+func (this LineTTYContext) Write(w *ColoredWriter, b []byte) error {
+	clr := this.TerminalColorName(w.GetColor())
+	style := w.GetStyle()
+	if !bool(this) || (clr.IsEmpty() && style == NoStyle) {
+		_, err := w.GetWriter().Write(b)
+		return err
+	}
+
+	out := w.GetWriter()
+	for len(b) != 0 {
+		line := b
+		eol := bytes.IndexByte(b, '\n')
+		if eol != -1 {
+			line = b[:eol]
+		}
+
+		if len(line) != 0 {
+			if err := writeTerminalColorName(out, clr); err != nil {
+				return err
+			}
+			if err := writeTerminalStyle(out, style); err != nil {
+				return err
+			}
+			if _, err := out.Write(line); err != nil {
+				return err
+			}
+			if _, err := out.Write(ttyResetColor); err != nil {
+				return err
+			}
+		}
+
+		if eol == -1 {
+			break
+		}
+		if _, err := out.Write(EOL); err != nil {
+			return err
+		}
+		b = b[eol+1:]
+	}
+	return nil
+}
+
+//endregion
+
 //region HTMLContext: a ``ColorContext`` that support HTML coloring
 type HTMLContext bool
 
@@ -311,29 +501,37 @@ var (
 
 func (this HTMLContext) Name() string { return "HTML" }
 func (this HTMLContext) Write(w *ColoredWriter, b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return writeHTMLColored(w.GetWriter(), w.GetColor().HtmlColorName(), w.GetStyle(), b)
+}
+
+func writeHTMLColored(out io.Writer, clr ColorName, style TextStyle, b []byte) error {
 	var err error
 	requireReset := false
-	if clr := w.GetColor().HtmlColorName(); !clr.IsEmpty() {
+	if !clr.IsEmpty() || style != NoStyle {
 		requireReset = true
 		clrHeader := `<span style="`
 		if clr.Foreground != "" {
-			clrHeader += "color: " + clr.Foreground
+			clrHeader += "color: " + clr.Foreground + ";"
 		}
 		if clr.Background != "" {
-			clrHeader += "background-color: " + clr.Background
+			clrHeader += "background-color: " + clr.Background + ";"
 		}
+		clrHeader += style.HtmlStyleName()
 		clrHeader += `">`
-		if _, err = w.GetWriter().Write([]byte(clrHeader)); err != nil {
+		if _, err = out.Write([]byte(clrHeader)); err != nil {
 			return err
 		}
 	}
 
-	if _, err = w.GetWriter().Write(b); err != nil {
+	if _, err = out.Write(b); err != nil {
 		return err
 	}
 
 	if requireReset {
-		_, err = w.GetWriter().Write(htmlEndColor)
+		_, err = out.Write(htmlEndColor)
 		return err
 	}
 	return nil
@@ -341,12 +539,118 @@ func (this HTMLContext) Write(w *ColoredWriter, b []byte) error {
 
 //endregion
 
+//region HTMLBackgroundContext: an ``HTMLContext`` that render foreground only colors on a known background
+
+// HTMLBackgroundContext render content as HTML and add ``Background`` to the colors that only have a foreground,
+// so text never inherit an unknown page background. If ``AutoContrast`` is set and the foreground is not readable
+// on ``Background``, black or white(whichever is more readable) is used as the background instead
+type HTMLBackgroundContext struct {
+	Background   Color
+	AutoContrast bool
+}
+
+func NewHTMLBackgroundContext(background Color, autoContrast bool) HTMLBackgroundContext {
+	if background == nil || background.Coverage() == NoCoverage {
+		panic("Invalid argument")
+	}
+	return HTMLBackgroundContext{Background: background.AsBackground(), AutoContrast: autoContrast}
+}
+
+func (this HTMLBackgroundContext) Name() string { return "HTML" }
+func (this HTMLBackgroundContext) Write(w *ColoredWriter, b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	color := w.GetColor()
+	clr := color.HtmlColorName()
+	if color.Coverage() == Foreground {
+		background := this.Background.AsBackground()
+		if this.AutoContrast && contrastRatio(color.Code(), background.Code()) < minReadableContrast {
+			background = RGBColor(contrastingBackground(color.Code())).AsBackground()
+		}
+		clr.Background = background.HtmlColorName().Background
+	}
+	return writeHTMLColored(w.GetWriter(), clr, w.GetStyle(), b)
+}
+
+//endregion
+
+//region MeasuringContext: a ``ColorContext`` that measure visible width of the content that written through it
+type MeasuringContext struct {
+	inner ColorContext
+	width int
+}
+
+// NewMeasuringContext Create a ``MeasuringContext`` that write its content using ``inner``
+func NewMeasuringContext(inner ColorContext) *MeasuringContext {
+	if inner == nil {
+		inner = MonoColor
+	}
+	return &MeasuringContext{inner: inner}
+}
+
+func (this *MeasuringContext) Name() string { return this.inner.Name() }
+func (this *MeasuringContext) Write(w *ColoredWriter, b []byte) error {
+	// ``b`` is the raw content, color escapes are added by the inner context
+	this.width += VisibleWidth(string(b))
+	return this.inner.Write(w, b)
+}
+
+// Width Number of terminal cells that is required to display the content written through this context, control
+// characters(including new lines) and escapes are not counted and wide runes count as 2(see ``VisibleWidth``)
+func (this *MeasuringContext) Width() int { return this.width }
+
+// Reset Reset measured width to 0
+func (this *MeasuringContext) Reset() { this.width = 0 }
+
+//endregion
+
+//region StripContext: a ``ColorContext`` that write plain text
+// StripContext ignore color and style of the content entirely and only write its bytes, so the output never
+// contain escape sequences or markup. Unlike ``MonoColor`` that is the non-colored state of ``TTY``, this
+// context is meant for capturing plain text, e.g. in log files or test buffers
+type StripContext bool
+
+func (this StripContext) Name() string { return "Strip" }
+func (this StripContext) Write(w *ColoredWriter, b []byte) error {
+	_, err := w.GetWriter().Write(b)
+	return err
+}
+
+// StripColors Render ``content`` as plain text without any color
+func StripColors(content interface{}) string {
+	builder := &strings.Builder{}
+	CWrite(builder, content, Strip)
+	return builder.String()
+}
+
+//endregion
+
 // Get default context that must used to write content to a writer.
-// This will return ``TTY`` if w is a TTY and ``MonoColor`` otherwise
+// If w is a TTY(or a pipe when ``SetColorPipes`` is enabled) this will return a ``TTY`` context that its color
+// depth is detected from ``COLORTERM`` and ``TERM`` environment variables(see ``DetectColorLevel``),
+// otherwise it return ``MonoColor``. ``FORCE_COLOR`` and ``NO_COLOR`` environment variables override
+// this detection, see ``ColorContextFromEnv``
 func GetDefaultContext(w io.Writer) ColorContext {
-	if f, ok := w.(*os.File); ok && IsTerminal(f) {
+	return ColorContextFromEnv(w)
+}
+
+// ContextForContentType Return the context that must be used to write content with ``contentType`` MIME type.
+// ``text/html`` and ``application/xhtml+xml`` use ``HTML``, ``text/x-ansi`` use ``TTY`` and any other type
+// use ``MonoColor``
+func ContextForContentType(contentType string) ColorContext {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return MonoColor
+	}
+
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return HTML
+	case "text/x-ansi":
 		return TTY
-	} else {
+	default:
 		return MonoColor
 	}
 }
@@ -359,6 +663,7 @@ func CContent(color Color, content interface{}) ColoredValue {
 	if cv, ok := content.(ColoredValue); ok {
 		return ColoredValue{
 			Color:   color,
+			Style:   cv.Style,
 			Content: cv.Content,
 		}
 	}
@@ -380,6 +685,22 @@ func CFormat(color Color, format string, args ...interface{}) ColoredValue {
 	}
 }
 
+// CContentIf Same as ``CContent`` but only apply ``color`` when ``cond`` is true, otherwise content is ``NoColor``
+func CContentIf(cond bool, color Color, content interface{}) ColoredValue {
+	if !cond {
+		color = NoColor
+	}
+	return CContent(color, content)
+}
+
+// CFormatIf Same as ``CFormat`` but only apply ``color`` when ``cond`` is true. Colors of arguments are kept anyway
+func CFormatIf(cond bool, color Color, format string, args ...interface{}) ColoredValue {
+	if !cond {
+		color = NoColor
+	}
+	return CFormat(color, format, args...)
+}
+
 // CWrite write a content to ``w`` using ``context`` or default context of ``w``
 func CWrite(w io.Writer, content interface{}, context ColorContext) error {
 	if context == nil {
@@ -410,6 +731,26 @@ func CWritefc(w io.Writer, context ColorContext, color Color, format string, arg
 	return CWrite(w, CFormat(color, format, args...), context)
 }
 
+// RenderMulti render ``content`` using each of the ``contexts`` and return the rendered strings in the same
+// order, a ``nil`` context is treated as ``MonoColor``
+func RenderMulti(content interface{}, contexts ...ColorContext) ([]string, error) {
+	result := make([]string, len(contexts))
+	builder := &strings.Builder{}
+	for i := 0; i < len(contexts); i++ {
+		context := contexts[i]
+		if context == nil {
+			context = MonoColor
+		}
+
+		builder.Reset()
+		if err := CWrite(builder, content, context); err != nil {
+			return nil, err
+		}
+		result[i] = builder.String()
+	}
+	return result, nil
+}
+
 const (
 	AliceBlue            RGBColor = 0xF0F8FF
 	AntiqueWhite         RGBColor = 0xFAEBD7
@@ -564,12 +905,20 @@ const (
 type ColorNameMap struct {
 	colorNamesByCode map[RGBCode]string
 	colorsByName     map[string]RGBCode
+	caseSensitive    bool
 }
 
 func NewColorNameMap(colorNamesByCode map[RGBCode]string) *ColorNameMap {
+	return NewColorNameMapEx(colorNamesByCode, false)
+}
+
+// NewColorNameMapEx Create a ``ColorNameMap``, if ``caseSensitive`` is true names are kept as-is and lookups
+// are case sensitive, otherwise names are lowercased and lookups ignore case
+func NewColorNameMapEx(colorNamesByCode map[RGBCode]string, caseSensitive bool) *ColorNameMap {
 	result := &ColorNameMap{
 		colorNamesByCode: make(map[RGBCode]string),
 		colorsByName:     make(map[string]RGBCode),
+		caseSensitive:    caseSensitive,
 	}
 
 	if colorNamesByCode != nil {
@@ -580,32 +929,138 @@ func NewColorNameMap(colorNamesByCode map[RGBCode]string) *ColorNameMap {
 
 	return result
 }
+func (this *ColorNameMap) IsCaseSensitive() bool { return this.caseSensitive }
+func (this *ColorNameMap) normalizeName(name string) string {
+	if this.caseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
 func (this *ColorNameMap) GetColorNameByCode(code RGBCode) string {
 	if name, ok := this.colorNamesByCode[code]; ok {
 		return name
 	}
 	return ""
 }
+
+// GetNearestColorName Return name and code of the registered color that is nearest to ``code`` by euclidean
+// distance in RGB space, or an empty name and ``NoColorCode`` if this map has no color
+func (this *ColorNameMap) GetNearestColorName(code RGBCode) (string, RGBCode) {
+	return this.GetNearestColorNameBy(code, colorDistance)
+}
+
+// GetNearestColorNameBy Same as ``GetNearestColorName`` but use ``distance`` to compare colors. Aliases that
+// added by ``AddName`` are not considered and ties are resolved in favor of the smaller code
+func (this *ColorNameMap) GetNearestColorNameBy(code RGBCode, distance func(a, b RGBCode) int) (string, RGBCode) {
+	resultName, resultCode := "", NoColorCode
+	bestDistance := -1
+	for candidate, name := range this.colorNamesByCode {
+		d := distance(code, candidate)
+		if bestDistance == -1 || d < bestDistance || (d == bestDistance && candidate < resultCode) {
+			resultName, resultCode = name, candidate
+			bestDistance = d
+		}
+	}
+	return resultName, resultCode
+}
 func (this *ColorNameMap) GetColorCodeByName(name string) RGBCode {
-	iname := strings.ToLower(name)
+	iname := this.normalizeName(name)
 	if code, ok := this.colorsByName[iname]; ok {
 		return code
 	}
 	return NoColorCode
 }
+
+// ParseColor Parse a foreground color like ``ParseColor`` but resolve color names using this map
+func (this *ColorNameMap) ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf(unknownColorNameFormat, s)
+	}
+
+	if s[0] == '#' {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return nil, ErrorInvalidColorCode
+		}
+		code, err := strconv.ParseUint(hex, 16, 24)
+		if err != nil {
+			return nil, ErrorInvalidColorCode
+		}
+		return RGBColor(uint32(code)), nil
+	}
+
+	if len(s) > 4 && strings.EqualFold(s[:4], "rgb(") {
+		if s[len(s)-1] != ')' {
+			return nil, ErrorInvalidColorCode
+		}
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return nil, ErrorInvalidColorCode
+		}
+		channels := [3]uint8{}
+		for i := 0; i < len(parts); i++ {
+			channel, err := strconv.ParseUint(strings.TrimSpace(parts[i]), 10, 8)
+			if err != nil {
+				return nil, ErrorInvalidColorCode
+			}
+			channels[i] = uint8(channel)
+		}
+		return RGBColor(RGB(channels[0], channels[1], channels[2])), nil
+	}
+
+	if code := this.GetColorCodeByName(s); code != NoColorCode {
+		return code.ToColor(), nil
+	}
+	return nil, fmt.Errorf(unknownColorNameFormat, s)
+}
+
 func (this *ColorNameMap) SetColorCodeName(code RGBCode, name string) *ColorNameMap {
 	this.colorNamesByCode[code] = name
-	iname := strings.ToLower(name)
+	iname := this.normalizeName(name)
 	this.colorsByName[iname] = code
 	return this
 }
 func (this *ColorNameMap) AddName(name string, code RGBCode) *ColorNameMap {
-	iname := strings.ToLower(name)
+	iname := this.normalizeName(name)
 	this.colorsByName[iname] = code
 	return this
 }
+
+// Names Return display names of all colors of this map, sorted. Aliases that added by ``AddName`` are not
+// included, use ``GetColorCodeByName`` to resolve them
+func (this *ColorNameMap) Names() []string {
+	result := make([]string, 0, len(this.colorNamesByCode))
+	for _, name := range this.colorNamesByCode {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Codes Return all color codes that have a display name in this map, sorted
+func (this *ColorNameMap) Codes() []RGBCode {
+	result := make([]RGBCode, 0, len(this.colorNamesByCode))
+	for code := range this.colorNamesByCode {
+		result = append(result, code)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// ForEach Call ``fn`` for each display name of this map(sorted by name) and its color code
+func (this *ColorNameMap) ForEach(fn func(name string, code RGBCode)) {
+	codes := this.Codes()
+	sort.Slice(codes, func(i, j int) bool { return this.colorNamesByCode[codes[i]] < this.colorNamesByCode[codes[j]] })
+	for i := 0; i < len(codes); i++ {
+		fn(this.colorNamesByCode[codes[i]], codes[i])
+	}
+}
 func (this *ColorNameMap) Clone() *ColorNameMap {
-	result := NewColorNameMap(nil)
+	result := NewColorNameMapEx(nil, this.caseSensitive)
 	for code, name := range this.colorNamesByCode {
 		result.colorNamesByCode[code] = name
 	}
@@ -615,7 +1070,7 @@ func (this *ColorNameMap) Clone() *ColorNameMap {
 	return result
 }
 
-var globalColorMap = NewColorNameMap(map[RGBCode]string{
+var builtinColorMap = NewColorNameMap(map[RGBCode]string{
 	AliceBlue.Code():            "AliceBlue",
 	AntiqueWhite.Code():         "AntiqueWhite",
 	Aqua.Code():                 "Aqua",
@@ -766,10 +1221,39 @@ var globalColorMap = NewColorNameMap(map[RGBCode]string{
 	YellowGreen.Code():          "YellowGreen",
 })
 
-func GetGlobalColorMap() *ColorNameMap           { return globalColorMap }
-func GetColorNameByCode(code RGBCode) string     { return globalColorMap.GetColorNameByCode(code) }
-func GetColorCodeByName(name string) RGBCode     { return globalColorMap.GetColorCodeByName(name) }
-func SetColorCodeName(code RGBCode, name string) { globalColorMap.SetColorCodeName(code, name) }
+var globalColorMap = func() *atomic.Pointer[ColorNameMap] {
+	result := &atomic.Pointer[ColorNameMap]{}
+	result.Store(builtinColorMap)
+	return result
+}()
+
+func GetGlobalColorMap() *ColorNameMap           { return globalColorMap.Load() }
+func GetColorNameByCode(code RGBCode) string     { return GetGlobalColorMap().GetColorNameByCode(code) }
+func GetColorCodeByName(name string) RGBCode     { return GetGlobalColorMap().GetColorCodeByName(name) }
+func SetColorCodeName(code RGBCode, name string) { GetGlobalColorMap().SetColorCodeName(code, name) }
+
+// GetNearestColorName Return name and code of the color of the global color map that is nearest to ``code``
+func GetNearestColorName(code RGBCode) (string, RGBCode) {
+	return GetGlobalColorMap().GetNearestColorName(code)
+}
+
+// ParseColor Parse a foreground color from ``#RGB``, ``#RRGGBB`` or ``rgb(r, g, b)`` forms or a name of the
+// global color map, so it accept output of ``RGBCode.String``
+func ParseColor(s string) (Color, error) { return GetGlobalColorMap().ParseColor(s) }
+
+// WithColorMap Use ``m`` as the global color map while ``fn`` is running and restore the old map after that,
+// even if ``fn`` panics.
+// Since global color map is shared by the whole process, other goroutines will also see ``m`` while ``fn`` is
+// running and overlapping calls from multiple goroutines may restore the maps in an unexpected order.
+func WithColorMap(m *ColorNameMap, fn func()) {
+	if m == nil || fn == nil {
+		panic("Invalid argument")
+	}
+
+	oldMap := globalColorMap.Swap(m)
+	defer globalColorMap.Store(oldMap)
+	fn()
+}
 
 type ContentWithContext struct {
 	Context ColorContext
@@ -788,3 +1272,37 @@ func (this ContentWithContext) String() string {
 	CWrite(builder, this.Content, this.Context)
 	return builder.String()
 }
+
+// countingWriter a writer that count bytes that written to its inner writer
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (this *countingWriter) Write(b []byte) (int, error) {
+	n, err := this.w.Write(b)
+	this.count += int64(n)
+	return n, err
+}
+
+// ColoredContentWriterTo an ``io.WriterTo`` that render a content using ``Context`` or default context of
+// the writer if ``Context`` is nil
+type ColoredContentWriterTo struct {
+	Context ColorContext
+	Content interface{}
+}
+
+func NewColoredContentWriterTo(context ColorContext, content interface{}) ColoredContentWriterTo {
+	return ColoredContentWriterTo{Context: context, Content: content}
+}
+
+func (this ColoredContentWriterTo) WriteTo(w io.Writer) (int64, error) {
+	context := this.Context
+	if context == nil {
+		context = GetDefaultContext(w)
+	}
+
+	cw := &countingWriter{w: w}
+	err := CWrite(cw, this.Content, context)
+	return cw.count, err
+}
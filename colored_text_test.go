@@ -0,0 +1,605 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColoredValueRestoresNoColor(t *testing.T) {
+	builder := &strings.Builder{}
+	w := NewColoredWriter(TTY, builder)
+	// color of ``a`` must not leak into ``b`` that is rendered after it with ``NoColor``
+	if err := w.WriteContent(CFormat(NoColor, "%v b", CContent(Red, "a"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteString(" c"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\033[38;2;255;0;0ma\033[0m b c"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestRGB(t *testing.T) {
+	if RGB(255, 0, 0) != Red.Code() {
+		t.Errorf("expected %s but received %s", Red.Code(), RGB(255, 0, 0))
+	}
+	code := RGB(0x12, 0x34, 0x56)
+	if code != 0x123456 || code.Red() != 0x12 || code.Green() != 0x34 || code.Blue() != 0x56 {
+		t.Errorf("unexpected channels of %s", code)
+	}
+}
+
+func TestRGBA(t *testing.T) {
+	code := RGBA(0x12, 0x34, 0x56, 0x80)
+	if code.Red() != 0x12 || code.Green() != 0x34 || code.Blue() != 0x56 || code.Alpha() != 0x80 {
+		t.Errorf("unexpected channels of %s", code)
+	}
+	if code.String() != "#12345680" {
+		t.Errorf("expected #12345680 but received %s", code)
+	}
+	if code.RGB() != 0x123456 {
+		t.Errorf("expected #123456 but received %s", code.RGB())
+	}
+
+	// alpha never leak into the reserved byte of an RGBCode
+	if RGBA(255, 255, 255, 0).RGB() != 0xFFFFFF {
+		t.Errorf("expected a transparent white to be white but received %s", RGBA(255, 255, 255, 0).RGB())
+	}
+	if color := RGBA(255, 0, 0, 0).RGB().ToColor(); color.Coverage() != Foreground {
+		t.Errorf("expected a foreground color but received %v", color.Coverage())
+	}
+}
+
+func TestRGBCodeToColor(t *testing.T) {
+	if color := NoColorCode.ToColor(); color != NoColor {
+		t.Errorf("expected NoColor but received %v", color)
+	}
+	if color := RGBCode(0x123456).ToColor(); !ColorsEqual(color, RGBColor(0x123456)) {
+		t.Errorf("expected #123456 but received %v", color)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected ToColor to panic for a code with a non-zero high byte")
+		}
+	}()
+	RGBCode(0x80123456).ToColor()
+}
+
+func TestRGBCodeString(t *testing.T) {
+	if s := RGBCode(0x0000FF).String(); s != "#0000FF" {
+		t.Errorf("expected #0000FF but received %s", s)
+	}
+}
+
+func TestHTMLContextStyle(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := CWrite(builder, CContent(MixColors(Red, Blue), "a"), HTML); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<span style="color: Red;background-color: Blue;">a</span>`
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestMeasuringContext(t *testing.T) {
+	context := NewMeasuringContext(TTY)
+	builder := &strings.Builder{}
+	content := CFormat(Blue, "%v: %v", CContent(Red, "héllo"), CContent(Green.AsBackground(), 42))
+	if err := CWrite(builder, content, context); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(builder.String(), "\033[") {
+		t.Errorf("expected colored output but received %q", builder.String())
+	}
+	plain := StripEscapes(builder.String())
+	if plain != "héllo: 42" {
+		t.Errorf("unexpected content %q", plain)
+	}
+	if context.Width() != len([]rune(plain)) {
+		t.Errorf("expected width %d but received %d", len([]rune(plain)), context.Width())
+	}
+
+	// new lines and control characters have no width and wide runes occupy 2 cells
+	context.Reset()
+	if err := CWrite(builder, CContent(Red, "a\n世界\t"), context); err != nil {
+		t.Fatal(err)
+	}
+	if context.Width() != 5 {
+		t.Errorf("expected width 5 but received %d", context.Width())
+	}
+}
+
+func TestColorNameMapNames(t *testing.T) {
+	global := GetGlobalColorMap()
+	// CSS names that share a code (e.g. Gray and Grey) keep a single display name
+	names := global.Names()
+	if len(names) != 139 {
+		t.Errorf("expected 139 built-in names but received %d", len(names))
+	}
+	if len(global.Codes()) != len(names) {
+		t.Errorf("expected %d codes but received %d", len(names), len(global.Codes()))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("expected sorted names but %q is before %q", names[i-1], names[i])
+		}
+	}
+
+	m := global.Clone()
+	m.SetColorCodeName(0x123456, "MyColor")
+	m.AddName("my-alias", 0x123456)
+	if len(m.Names()) != len(names)+1 {
+		t.Errorf("expected %d names but received %d", len(names)+1, len(m.Names()))
+	}
+	found := false
+	m.ForEach(func(name string, code RGBCode) {
+		if name == "my-alias" {
+			t.Errorf("expected aliases to be excluded")
+		}
+		if name == "MyColor" {
+			found = true
+			if code != 0x123456 {
+				t.Errorf("expected #123456 but received %s", code)
+			}
+		}
+	})
+	if !found {
+		t.Errorf("expected MyColor to be enumerated")
+	}
+	if m.GetColorCodeByName("my-alias") != 0x123456 {
+		t.Errorf("expected alias to be resolvable")
+	}
+	if len(GetGlobalColorMap().Names()) != len(names) {
+		t.Errorf("expected global map to be unchanged")
+	}
+}
+
+func TestLineTTYContext(t *testing.T) {
+	builder := &strings.Builder{}
+	w := NewColoredWriter(LineTTY, builder)
+	if err := w.WriteContent(CContent(Red, "a\nb\n\nc")); err != nil {
+		t.Fatal(err)
+	}
+
+	red := "\033[38;2;255;0;0m"
+	expected := red + "a\033[0m\n" + red + "b\033[0m\n\n" + red + "c\033[0m"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestLineTTYContextWithoutColor(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := NewColoredWriter(LineTTY, builder).WriteString("a\nb"); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "a\nb" {
+		t.Errorf("expected %q but received %q", "a\nb", builder.String())
+	}
+
+	builder.Reset()
+	if err := NewColoredWriter(LineTTYContext(false), builder).WriteContent(CContent(Red, "a\nb")); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "a\nb" {
+		t.Errorf("expected %q but received %q", "a\nb", builder.String())
+	}
+}
+
+func TestColorsEqual(t *testing.T) {
+	if !ColorsEqual(Red, RGBColor(0xFF0000)) || !Red.Equals(Red.AsForeground()) {
+		t.Errorf("expected same foreground colors to be equal")
+	}
+	if ColorsEqual(Red, Red.AsBackground()) || Red.Equals(Red.AsBackground()) {
+		t.Errorf("expected same code with different coverage not to be equal")
+	}
+	if !ColorsEqual(Red.AsBackground(), Red.Toggle()) {
+		t.Errorf("expected same background colors to be equal")
+	}
+
+	if ColorsEqual(NoColor, Black) || Black.Equals(NoColor) {
+		t.Errorf("expected NoColor not to be equal to a real color")
+	}
+	if !ColorsEqual(NoColor, nil) || !ColorsEqual(nil, NoColor) {
+		t.Errorf("expected nil to be equal to NoColor")
+	}
+
+	if !ColorsEqual(MixColors(Red, Blue), Red.WithBackground(Blue)) {
+		t.Errorf("expected mixed colors with same parts to be equal")
+	}
+	if ColorsEqual(MixColors(Red, Blue), MixColors(Red, Green)) {
+		t.Errorf("expected mixed colors with different backgrounds not to be equal")
+	}
+	if ColorsEqual(MixColors(Red, Blue), MixColors(Blue, Blue)) {
+		t.Errorf("expected mixed colors with different foregrounds not to be equal")
+	}
+	if ColorsEqual(MixColors(Red, Blue), Red) {
+		t.Errorf("expected a mixed color not to be equal to its foreground")
+	}
+}
+
+func TestEmptyWriteProduceNoOutput(t *testing.T) {
+	contexts := []ColorContext{TTY, HTML, LineTTY}
+	for i := 0; i < len(contexts); i++ {
+		builder := &strings.Builder{}
+		w := NewColoredWriter(contexts[i], builder)
+		w.SetColor(Red.WithBackground(Blue))
+		if err := w.Write([]byte{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteContent(CContent(Green, "")); err != nil {
+			t.Fatal(err)
+		}
+		if builder.Len() != 0 {
+			t.Errorf("%s: expected no output but received %q", contexts[i].Name(), builder.String())
+		}
+	}
+}
+
+func TestWithColorMap(t *testing.T) {
+	theme := GetGlobalColorMap().Clone().SetColorCodeName(0x123456, "Red")
+	global := GetGlobalColorMap()
+
+	WithColorMap(theme, func() {
+		if GetGlobalColorMap() != theme {
+			t.Errorf("expected theme to be the global map inside fn")
+		}
+		if code := GetColorCodeByName("red"); code != 0x123456 {
+			t.Errorf("expected #123456 inside fn but received %s", code)
+		}
+	})
+	if GetGlobalColorMap() != global {
+		t.Errorf("expected global map to be restored")
+	}
+	if code := GetColorCodeByName("red"); code != Red.Code() {
+		t.Errorf("expected %s after fn but received %s", Red.Code(), code)
+	}
+}
+
+func TestWithColorMapRestoreOnPanic(t *testing.T) {
+	global := GetGlobalColorMap()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic of fn to be propagated")
+			}
+		}()
+		WithColorMap(global.Clone(), func() { panic("failure") })
+	}()
+	if GetGlobalColorMap() != global {
+		t.Errorf("expected global map to be restored after a panic")
+	}
+}
+
+func TestColorNameMapCaseSensitivity(t *testing.T) {
+	insensitive := NewColorNameMap(map[RGBCode]string{0x111111: "Dark"})
+	if insensitive.IsCaseSensitive() {
+		t.Errorf("expected NewColorNameMap to be case insensitive")
+	}
+	if insensitive.GetColorCodeByName("dark") != 0x111111 || insensitive.GetColorCodeByName("DARK") != 0x111111 {
+		t.Errorf("expected lookups to ignore case")
+	}
+	// names that only differ in case collide
+	insensitive.SetColorCodeName(0x222222, "DARK")
+	if insensitive.GetColorCodeByName("Dark") != 0x222222 {
+		t.Errorf("expected last name to win in a case insensitive map")
+	}
+
+	sensitive := NewColorNameMapEx(map[RGBCode]string{0x111111: "Dark"}, true)
+	if !sensitive.IsCaseSensitive() {
+		t.Errorf("expected NewColorNameMapEx(..., true) to be case sensitive")
+	}
+	sensitive.SetColorCodeName(0x222222, "DARK")
+	if sensitive.GetColorCodeByName("Dark") != 0x111111 || sensitive.GetColorCodeByName("DARK") != 0x222222 {
+		t.Errorf("expected names that differ in case to be kept separately")
+	}
+	if sensitive.GetColorCodeByName("dark") != NoColorCode {
+		t.Errorf("expected lookups to respect case")
+	}
+	if sensitive.GetColorNameByCode(0x111111) != "Dark" || insensitive.GetColorNameByCode(0x222222) != "DARK" {
+		t.Errorf("expected display names to keep their case in both modes")
+	}
+	if !sensitive.Clone().IsCaseSensitive() {
+		t.Errorf("expected clone to keep case sensitivity")
+	}
+}
+
+func TestColoredContentWriterTo(t *testing.T) {
+	builder := &strings.Builder{}
+	n, err := NewColoredContentWriterTo(TTY, CFormat(NoColor, "%v b", CContent(Red, "a"))).WriteTo(builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\033[38;2;255;0;0ma\033[0m b"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+	if n != int64(builder.Len()) {
+		t.Errorf("expected %d bytes but received %d", builder.Len(), n)
+	}
+}
+
+func TestColoredContentWriterToDefaultContext(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+
+	// a buffer is not a terminal, so it must be rendered without colors
+	builder := &strings.Builder{}
+	n, err := NewColoredContentWriterTo(nil, CContent(Red, "abc")).WriteTo(builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "abc" {
+		t.Errorf("expected %q but received %q", "abc", builder.String())
+	}
+	if n != 3 {
+		t.Errorf("expected 3 bytes but received %d", n)
+	}
+}
+
+func TestContextForContentType(t *testing.T) {
+	cases := []struct {
+		contentType string
+		expected    ColorContext
+	}{
+		{"text/html", HTML},
+		{"text/html; charset=utf-8", HTML},
+		{"application/xhtml+xml", HTML},
+		{"text/x-ansi", TTY},
+		{"text/plain", MonoColor},
+		{"application/json", MonoColor},
+		{"", MonoColor},
+		{"not a content type;;", MonoColor},
+	}
+	for i := 0; i < len(cases); i++ {
+		if ctx := ContextForContentType(cases[i].contentType); ctx != cases[i].expected {
+			t.Errorf("%q: expected %T but received %T", cases[i].contentType, cases[i].expected, ctx)
+		}
+	}
+}
+
+func TestRGBColorWithBackground(t *testing.T) {
+	color := Red.WithBackground(White)
+	if color.Coverage() != Both {
+		t.Errorf("expected a color with both coverages but received %v", color.Coverage())
+	}
+	if name := color.HtmlColorName(); name != (ColorName{Foreground: "Red", Background: "White"}) {
+		t.Errorf("unexpected HTML name %+v", name)
+	}
+	if name := color.TerminalColorName(); name != (ColorName{Foreground: "38;2;255;0;0", Background: "48;2;255;255;255"}) {
+		t.Errorf("unexpected terminal name %+v", name)
+	}
+}
+
+func TestRGBColorWithForeground(t *testing.T) {
+	// receiver is used as the background even if it is a foreground color
+	color := White.WithForeground(Red)
+	if !ColorsEqual(color, MixColors(Red, White)) {
+		t.Errorf("expected red on white but received %+v", color.HtmlColorName())
+	}
+	if name := color.HtmlColorName(); name != (ColorName{Foreground: "Red", Background: "White"}) {
+		t.Errorf("unexpected HTML name %+v", name)
+	}
+	if name := Blue.AsBackground().(RGBColor).WithForeground(Red).TerminalColorName(); name !=
+		(ColorName{Foreground: "38;2;255;0;0", Background: "48;2;0;0;255"}) {
+		t.Errorf("unexpected terminal name %+v", name)
+	}
+}
+
+func TestRenderMulti(t *testing.T) {
+	content := CFormat(NoColor, "%v b", CContent(Red, "a"))
+	result, err := RenderMulti(content, MonoColor, TTY, HTML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"a b",
+		"\033[38;2;255;0;0ma\033[0m b",
+		`<span style="color: Red;">a</span> b`,
+		"a b",
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d results but received %q", len(expected), result)
+	}
+	for i := 0; i < len(expected); i++ {
+		if result[i] != expected[i] {
+			t.Errorf("context %d: expected %q but received %q", i, expected[i], result[i])
+		}
+	}
+
+	if result, err = RenderMulti(content); err != nil || len(result) != 0 {
+		t.Errorf("expected no result for no context but received %q(%v)", result, err)
+	}
+}
+
+func TestHTMLBackgroundContext(t *testing.T) {
+	cases := []struct {
+		context  HTMLBackgroundContext
+		color    Color
+		expected string
+	}{
+		// readable foreground is rendered on the configured background
+		{NewHTMLBackgroundContext(White, true), Navy, `<span style="color: Navy;background-color: White;">a</span>`},
+		{NewHTMLBackgroundContext(White, false), Yellow, `<span style="color: Yellow;background-color: White;">a</span>`},
+		// unreadable foreground use black or white instead
+		{NewHTMLBackgroundContext(White, true), Yellow, `<span style="color: Yellow;background-color: Black;">a</span>`},
+		{NewHTMLBackgroundContext(Black, true), Navy, `<span style="color: Navy;background-color: White;">a</span>`},
+		// colors that have a background are kept as is
+		{NewHTMLBackgroundContext(White, true), MixColors(Yellow, Navy), `<span style="color: Yellow;background-color: Navy;">a</span>`},
+		{NewHTMLBackgroundContext(White, true), Red.AsBackground(), `<span style="background-color: Red;">a</span>`},
+		{NewHTMLBackgroundContext(White, true), NoColor, `a`},
+	}
+	for i := 0; i < len(cases); i++ {
+		builder := &strings.Builder{}
+		if err := CWrite(builder, CContent(cases[i].color, "a"), cases[i].context); err != nil {
+			t.Fatal(err)
+		}
+		if builder.String() != cases[i].expected {
+			t.Errorf("%d: expected %q but received %q", i, cases[i].expected, builder.String())
+		}
+	}
+}
+
+func TestRGBColorCoverageToggle(t *testing.T) {
+	fg := RGBColor(0x123456)
+	if !fg.IsForeground() || fg.IsBackground() {
+		t.Errorf("expected %v to be a foreground", fg)
+	}
+
+	bg := fg.Toggle()
+	if !bg.IsBackground() || bg.IsForeground() || bg.Coverage() != Background {
+		t.Errorf("expected toggled color to be a background")
+	}
+	if bg.Code() != fg.Code() {
+		t.Errorf("expected toggle to keep the code but received %s", bg.Code())
+	}
+	if bg != fg.AsBackground() {
+		t.Errorf("expected toggled color to be equal to AsBackground")
+	}
+	if bg.Toggle() != fg {
+		t.Errorf("expected toggling twice to return the original color")
+	}
+}
+
+func TestCContentIf(t *testing.T) {
+	for _, value := range []int{5, 50} {
+		builder := &strings.Builder{}
+		if err := CWrite(builder, CContentIf(value > 10, Red, value), TTY); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "5"
+		if value > 10 {
+			expected = "\033[38;2;255;0;0m50\033[0m"
+		}
+		if builder.String() != expected {
+			t.Errorf("expected %q but received %q", expected, builder.String())
+		}
+	}
+}
+
+func TestCFormatIf(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := CWrite(builder, CFormatIf(true, Red, "n=%d", 5), TTY); err != nil {
+		t.Fatal(err)
+	}
+	// every node of the format is colored separately
+	expected := "\033[38;2;255;0;0mn=\033[0m\033[38;2;255;0;0m5\033[0m"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+
+	// colors of the arguments are kept when the condition is false
+	builder.Reset()
+	if err := CWrite(builder, CFormatIf(false, Red, "n=%v", CContent(Blue, 5)), TTY); err != nil {
+		t.Fatal(err)
+	}
+	if expected = "n=\033[38;2;0;0;255m5\033[0m"; builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected RGBCode
+	}{
+		{"#FF8000", 0xFF8000},
+		{"#ff8000", 0xFF8000},
+		{"#F80", 0xFF8800},
+		{"rgb(255, 128, 0)", 0xFF8000},
+		{"RGB(1,2,3)", 0x010203},
+		{" Red ", 0xFF0000},
+		{"navy", 0x000080},
+	}
+	for i := 0; i < len(cases); i++ {
+		color, err := ParseColor(cases[i].s)
+		if err != nil || color.Code() != cases[i].expected || color.Coverage() != Foreground {
+			t.Errorf("%q: expected %s but received %v(%v)", cases[i].s, cases[i].expected, color, err)
+		}
+	}
+
+	// result of RGBCode.String must be parsed back
+	code := RGBCode(0x123456)
+	if color, err := ParseColor(code.String()); err != nil || color.Code() != code {
+		t.Errorf("expected %s to round trip but received %v(%v)", code, color, err)
+	}
+
+	invalid := []string{"#12", "#12345G", "#1234567", "rgb(1,2)", "rgb(1,2,256)", "rgb(1,2,3", ""}
+	for i := 0; i < len(invalid); i++ {
+		if _, err := ParseColor(invalid[i]); err == nil {
+			t.Errorf("%q: expected an error", invalid[i])
+		}
+	}
+	if _, err := ParseColor("NotAColor"); err == nil || !strings.Contains(err.Error(), "NotAColor") {
+		t.Errorf("expected an unknown color name error but received %v", err)
+	}
+}
+
+func TestColorNameMapParseColor(t *testing.T) {
+	m := NewColorNameMap(nil).AddName("brand", 0x336699)
+	if color, err := m.ParseColor("brand"); err != nil || color.Code() != 0x336699 {
+		t.Errorf("expected #336699 but received %v(%v)", color, err)
+	}
+	if _, err := m.ParseColor("Red"); err == nil {
+		t.Errorf("expected names of the global map not to be resolved")
+	}
+	if color, err := m.ParseColor("#abc"); err != nil || color.Code() != 0xAABBCC {
+		t.Errorf("expected #AABBCC but received %v(%v)", color, err)
+	}
+}
+
+func TestStripColors(t *testing.T) {
+	content := CFormat(Red, "a %v %s", CContent(MixColors(Blue, White), "b").WithStyle(Bold|Underline), CContent(NoColor, "c"))
+	if s := StripColors(content); s != "a b c" {
+		t.Errorf("expected %q but received %q", "a b c", s)
+	}
+	if s := StripColors("plain"); s != "plain" {
+		t.Errorf("expected %q but received %q", "plain", s)
+	}
+
+	builder := &strings.Builder{}
+	if err := CWrite(builder, content, Strip); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "a b c" {
+		t.Errorf("expected %q but received %q", "a b c", builder.String())
+	}
+}
+
+func TestColorNameMapGetNearestColorName(t *testing.T) {
+	m := NewColorNameMap(map[RGBCode]string{0x000000: "black", 0xFF0000: "red", 0xFFFFFF: "white"})
+	tests := []struct {
+		code         RGBCode
+		expectedName string
+		expectedCode RGBCode
+	}{
+		{0xFF0000, "red", 0xFF0000},
+		{0xE01010, "red", 0xFF0000},
+		{0x202020, "black", 0x000000},
+		{0xEEEEEE, "white", 0xFFFFFF},
+	}
+	for _, test := range tests {
+		name, code := m.GetNearestColorName(test.code)
+		if name != test.expectedName || code != test.expectedCode {
+			t.Errorf("GetNearestColorName(%06X): expected %q(%06X) but received %q(%06X)",
+				test.code, test.expectedName, test.expectedCode, name, code)
+		}
+	}
+
+	// ties are resolved in favor of the smaller code
+	if name, _ := m.GetNearestColorNameBy(0x123456, func(a, b RGBCode) int { return 0 }); name != "black" {
+		t.Errorf("expected %q but received %q", "black", name)
+	}
+
+	if name, code := NewColorNameMap(nil).GetNearestColorName(0x123456); name != "" || code != NoColorCode {
+		t.Errorf("expected no color from an empty map but received %q(%06X)", name, code)
+	}
+}
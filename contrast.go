@@ -0,0 +1,61 @@
+package helpers
+
+import "math"
+
+// minReadableContrast minimum contrast ratio of normal text according to WCAG AA
+const minReadableContrast = 4.5
+
+func linearChannel(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.03928 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// relativeLuminance Return relative luminance of a color as defined by WCAG
+func relativeLuminance(code RGBCode) float64 {
+	return 0.2126*linearChannel(code.Red()) + 0.7152*linearChannel(code.Green()) + 0.0722*linearChannel(code.Blue())
+}
+
+// contrastRatio Return WCAG contrast ratio of two colors, it is in [1, 21]
+func contrastRatio(a, b RGBCode) float64 {
+	la := relativeLuminance(a)
+	lb := relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// contrastingBackground Return black or white, whichever has more contrast with `fg`
+func contrastingBackground(fg RGBCode) RGBCode {
+	if contrastRatio(fg, 0x000000) >= contrastRatio(fg, 0xFFFFFF) {
+		return 0x000000
+	}
+	return 0xFFFFFF
+}
+
+// ContrastRatio Return WCAG contrast ratio of `fg` on `bg`, it is in [1, 21]
+func ContrastRatio(fg, bg RGBColor) float64 { return contrastRatio(fg.Code(), bg.Code()) }
+
+// IsReadable Check if normal text with `fg` color is readable on `bg` according to WCAG AA(contrast of 4.5:1)
+func IsReadable(fg, bg RGBColor) bool { return ContrastRatio(fg, bg) >= minReadableContrast }
+
+// BestForeground Return the candidate that has the highest contrast with `bg`, black or white if there is no
+// candidate. Result is always a foreground color
+func BestForeground(bg RGBColor, candidates ...RGBColor) RGBColor {
+	if len(candidates) == 0 {
+		return RGBColor(contrastingBackground(bg.Code()))
+	}
+
+	result := candidates[0]
+	bestRatio := ContrastRatio(result, bg)
+	for i := 1; i < len(candidates); i++ {
+		if ratio := ContrastRatio(candidates[i], bg); ratio > bestRatio {
+			result = candidates[i]
+			bestRatio = ratio
+		}
+	}
+	return result.AsForeground().(RGBColor)
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	black := RGBColor(0x000000)
+	white := RGBColor(0xFFFFFF)
+	if ratio := ContrastRatio(black, white); math.Abs(ratio-21) > 1e-9 {
+		t.Errorf("expected 21 for black on white but received %v", ratio)
+	}
+	if ratio := ContrastRatio(white, black); math.Abs(ratio-21) > 1e-9 {
+		t.Errorf("expected 21 for white on black but received %v", ratio)
+	}
+
+	colors := []RGBColor{black, white, 0xFF0000, 0x123456, 0x808080}
+	for i := 0; i < len(colors); i++ {
+		if ratio := ContrastRatio(colors[i], colors[i]); ratio != 1 {
+			t.Errorf("%06X: expected 1 on itself but received %v", uint32(colors[i]), ratio)
+		}
+	}
+
+	// coverage of the colors does not matter
+	if ratio := ContrastRatio(black, white.Toggle()); math.Abs(ratio-21) > 1e-9 {
+		t.Errorf("expected 21 for black on a white background but received %v", ratio)
+	}
+}
+
+func TestIsReadable(t *testing.T) {
+	white := RGBColor(0xFFFFFF)
+	// #767676 is the lightest gray that reach 4.5:1 on white
+	if ratio := ContrastRatio(0x767676, white); ratio < 4.5 || !IsReadable(0x767676, white) {
+		t.Errorf("expected #767676 to be readable on white but its contrast is %v", ratio)
+	}
+	if ratio := ContrastRatio(0x777777, white); ratio >= 4.5 || IsReadable(0x777777, white) {
+		t.Errorf("expected #777777 to be unreadable on white but its contrast is %v", ratio)
+	}
+	if !IsReadable(0x000000, white) || IsReadable(white, white) {
+		t.Errorf("unexpected readability of black and white on white")
+	}
+}
+
+func TestBestForeground(t *testing.T) {
+	bg := RGBColor(0xFFFFFF)
+	if color := BestForeground(bg, 0xFFFF00, 0x000080, 0x808080); color != 0x000080 {
+		t.Errorf("expected #000080 but received %08X", uint32(color))
+	}
+	if color := BestForeground(RGBColor(0x000000), 0x000080, 0xFFFF00, 0x808080); color != 0xFFFF00 {
+		t.Errorf("expected #FFFF00 but received %08X", uint32(color))
+	}
+
+	// result is a foreground even if the candidate is a background
+	if color := BestForeground(bg, RGBColor(0x000080).Toggle(), 0xFFFF00); !color.IsForeground() || color.Code() != 0x000080 {
+		t.Errorf("expected a foreground #000080 but received %08X", uint32(color))
+	}
+
+	// black or white is used when there is no candidate
+	if color := BestForeground(RGBColor(0xFFFFE0)); color != 0x000000 {
+		t.Errorf("expected black on a light background but received %08X", uint32(color))
+	}
+	if color := BestForeground(RGBColor(0x202040).Toggle()); color != 0xFFFFFF {
+		t.Errorf("expected a foreground white on a dark background but received %08X", uint32(color))
+	}
+}
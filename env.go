@@ -1,6 +1,9 @@
 package helpers
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // ReadEnv Read an environment variable or a default value
 func ReadEnv(envName, defaultValue string) string {
@@ -10,3 +13,16 @@ func ReadEnv(envName, defaultValue string) string {
 	}
 	return value
 }
+
+// ReadEnvInt Read an integer environment variable or a default value if it is not set or it is not a valid integer
+func ReadEnvInt(envName string, defaultValue int) int {
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
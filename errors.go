@@ -3,6 +3,7 @@ package helpers
 import (
 	"errors"
 	"fmt"
+	"io"
 )
 
 //region StringError
@@ -97,3 +98,30 @@ func (this AggregateError) As(target interface{}) bool {
 }
 
 //endregion
+
+//region Must
+
+// Must Return `value` or panic if `err` is not nil, e.g. `Must(LoadCertAndKeyFromFile(file))`
+func Must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+//endregion
+
+//region CloseAll
+
+// CloseAll Close all `closers`(skipping nil ones) even if some of them fail and return their aggregated errors
+func CloseAll(closers ...io.Closer) error {
+	builder := AggregateErrorBuilder{}
+	for i := 0; i < len(closers); i++ {
+		if closers[i] != nil {
+			builder.AddError(closers[i].Close())
+		}
+	}
+	return builder.GetError()
+}
+
+//endregion
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	if value := Must(strconv.Atoi("42")); value != 42 {
+		t.Errorf("expected 42 but received %d", value)
+	}
+}
+
+func TestMustPanic(t *testing.T) {
+	_, expected := strconv.Atoi("x")
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || err.Error() != expected.Error() {
+			t.Errorf("expected panic with %v but received %v", expected, err)
+		}
+	}()
+
+	Must(strconv.Atoi("x"))
+	t.Errorf("expected Must to panic")
+}
+
+type testCloser struct {
+	err    error
+	closed bool
+}
+
+func (this *testCloser) Close() error {
+	this.closed = true
+	return this.err
+}
+
+func TestCloseAll(t *testing.T) {
+	errA := StringError("a failed")
+	errB := StringError("b failed")
+	closers := []*testCloser{{err: errA}, {}, {err: errB}, {}}
+
+	err := CloseAll(closers[0], nil, closers[1], closers[2], closers[3])
+	for i := 0; i < len(closers); i++ {
+		if !closers[i].closed {
+			t.Errorf("expected closer %d to be closed", i)
+		}
+	}
+
+	aggregated, ok := err.(AggregateError)
+	if !ok || len(aggregated) != 2 {
+		t.Fatalf("expected 2 aggregated errors but received %v", err)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected both errors to be aggregated but received %v", aggregated)
+	}
+}
+
+func TestCloseAllSingleOrNoError(t *testing.T) {
+	if err := CloseAll(); err != nil {
+		t.Errorf("expected nil error but received %v", err)
+	}
+	if err := CloseAll(&testCloser{}, nil, &testCloser{}); err != nil {
+		t.Errorf("expected nil error but received %v", err)
+	}
+
+	var closers []io.Closer
+	closers = append(closers, &testCloser{}, &testCloser{err: io.ErrClosedPipe})
+	if err := CloseAll(closers...); err != io.ErrClosedPipe {
+		t.Errorf("expected %v but received %v", io.ErrClosedPipe, err)
+	}
+}
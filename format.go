@@ -31,6 +31,29 @@ func (this FormatInfo) Format() string {
 	return builder.String()
 }
 
+// VerbCount Number of the verbs of the format string, literal `%%` is not counted
+func (this FormatInfo) VerbCount() int {
+	result := 0
+	for i := 0; i < len(this); i++ {
+		if this[i].FormatString != "" && this[i].FormatString != "%%" {
+			result++
+		}
+	}
+	return result
+}
+
+// ArgCount Number of the arguments that bound to verbs of the format string, it is less than `VerbCount`
+// when too few arguments passed to `ParseFormatString`
+func (this FormatInfo) ArgCount() int {
+	result := 0
+	for i := 0; i < len(this); i++ {
+		if this[i].FormatString != "" && !this[i].NoArg {
+			result++
+		}
+	}
+	return result
+}
+
 func ParseFormatString(format string, args ...interface{}) FormatInfo {
 	i := 0
 	arg := 0
@@ -67,7 +90,10 @@ func ParseFormatString(format string, args ...interface{}) FormatInfo {
 		node := FormatNode{
 			FormatString: format[lastI:i],
 		}
-		if arg < len(args) {
+		if node.FormatString == "%%" {
+			// literal percent sign does not consume any argument
+			node.NoArg = true
+		} else if arg < len(args) {
 			node.Arg = args[arg]
 			arg++
 		} else {
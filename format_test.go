@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatInfoCounts(t *testing.T) {
+	cases := []struct {
+		format    string
+		args      []interface{}
+		verbs     int
+		argsCount int
+		formatted string
+	}{
+		{"plain text", nil, 0, 0, "plain text"},
+		{"100%%", nil, 0, 0, "100%"},
+		{"%d%%%d", []interface{}{1, 2}, 2, 2, "1%2"},
+		{"a %d b %% c %s", []interface{}{1, "x"}, 2, 2, "a 1 b % c x"},
+		{"%d %s %v", []interface{}{1}, 3, 1, "1 %!s(MISSING) %!v(MISSING)"},
+		{"%5.2f|%-3d", []interface{}{1.5, 7}, 2, 2, " 1.50|7  "},
+	}
+	for i := 0; i < len(cases); i++ {
+		info := ParseFormatString(cases[i].format, cases[i].args...)
+		if n := info.VerbCount(); n != cases[i].verbs {
+			t.Errorf("%q: expected %d verbs but received %d", cases[i].format, cases[i].verbs, n)
+		}
+		if n := info.ArgCount(); n != cases[i].argsCount {
+			t.Errorf("%q: expected %d args but received %d", cases[i].format, cases[i].argsCount, n)
+		}
+		if s := info.Format(); s != cases[i].formatted {
+			t.Errorf("%q: expected %q but received %q", cases[i].format, cases[i].formatted, s)
+		}
+	}
+}
+
+func TestFormatInfoRenderTo(t *testing.T) {
+	info := ParseFormatString("%d apples for %s, %d%%", 3, "bob", 50)
+	resolveColor := func(node FormatNode) Color {
+		switch node.Arg.(type) {
+		case int:
+			return Red
+		case string:
+			if node.FormatString != "" {
+				return Blue
+			}
+		}
+		return nil
+	}
+
+	builder := &strings.Builder{}
+	if err := info.RenderTo(NewColoredWriter(TTY, builder), resolveColor); err != nil {
+		t.Fatal(err)
+	}
+	red := "\033[38;2;255;0;0m"
+	blue := "\033[38;2;0;0;255m"
+	reset := "\033[0m"
+	expected := red + "3" + reset + " apples for " + blue + "bob" + reset + ", " + red + "50" + reset + "%"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+
+	builder.Reset()
+	if err := info.RenderTo(NewColoredWriter(TTY, builder), nil); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "3 apples for bob, 50%" {
+		t.Errorf("expected %q but received %q", "3 apples for bob, 50%", builder.String())
+	}
+}
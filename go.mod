@@ -1,8 +1,13 @@
 module github.com/devops-simba/helpers
 
-go 1.15
+go 1.20
 
 require (
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	golang.org/x/crypto v0.0.0-20201124201722-c8d3bf9c5392
 )
+
+require (
+	golang.org/x/sys v0.0.0-20191026070338-33540a1f6037 // indirect
+	golang.org/x/term v0.0.0-20201117132131-f5c789dd3221 // indirect
+)
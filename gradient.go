@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"math"
+)
+
+// Lerp Linearly interpolate each channel of this color toward `other`, `t` is clamped to [0, 1] and coverage of
+// the result is coverage of this color
+func (this RGBColor) Lerp(other RGBColor, t float64) RGBColor {
+	t = clampUnit(t)
+	from := this.Code()
+	to := other.Code()
+	lerp := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t)) }
+	code := RGB(lerp(from.Red(), to.Red()), lerp(from.Green(), to.Green()), lerp(from.Blue(), to.Blue()))
+	return RGBColor(code) | (this & 0x80000000)
+}
+
+// Gradient Return `steps` colors that are evenly spaced from `from` to `to`(inclusive)
+func Gradient(from, to RGBColor, steps int) []RGBColor {
+	if steps <= 0 {
+		return []RGBColor{}
+	}
+	if steps == 1 {
+		return []RGBColor{from}
+	}
+
+	result := make([]RGBColor, steps)
+	for i := 0; i < steps; i++ {
+		result[i] = from.Lerp(to, float64(i)/float64(steps-1))
+	}
+	return result
+}
+
+// GradientContent a text that each of its runes is colored along a gradient
+type GradientContent struct {
+	Text string
+	From RGBColor
+	To   RGBColor
+}
+
+// CGradient Color each rune of `text` along the gradient from `from` to `to`
+func CGradient(text string, from, to RGBColor) ColoredContent {
+	return GradientContent{Text: text, From: from, To: to}
+}
+
+func (this GradientContent) Render(w *ColoredWriter) error {
+	runes := []rune(this.Text)
+	colors := Gradient(this.From, this.To, len(runes))
+	for i := 0; i < len(runes); i++ {
+		if err := w.WriteContent(CContent(colors[i], string(runes[i]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
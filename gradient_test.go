@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRGBColorLerp(t *testing.T) {
+	from := RGBColor(0x000000)
+	to := RGBColor(0xFF8040)
+	cases := []struct {
+		t        float64
+		expected RGBColor
+	}{
+		{0, 0x000000},
+		{0.5, 0x804020},
+		{1, 0xFF8040},
+		{-1, 0x000000},
+		{2, 0xFF8040},
+	}
+	for i := 0; i < len(cases); i++ {
+		if color := from.Lerp(to, cases[i].t); color != cases[i].expected {
+			t.Errorf("Lerp(%v): expected %06X but received %08X", cases[i].t, uint32(cases[i].expected), uint32(color))
+		}
+	}
+
+	// coverage is taken from `from` and coverage of `other` is ignored
+	background := RGBColor(0x000000).Toggle()
+	if color := background.Lerp(to, 1); !color.IsBackground() || color.Code() != 0xFF8040 {
+		t.Errorf("expected a background #FF8040 but received %08X", uint32(color))
+	}
+	if color := from.Lerp(RGBColor(0xFF8040).Toggle(), 1); !color.IsForeground() || color.Code() != 0xFF8040 {
+		t.Errorf("expected a foreground #FF8040 but received %08X", uint32(color))
+	}
+}
+
+func TestGradient(t *testing.T) {
+	from := RGBColor(0x000000)
+	to := RGBColor(0x0000FF)
+	if colors := Gradient(from, to, 0); colors == nil || len(colors) != 0 {
+		t.Errorf("expected an empty gradient but received %v", colors)
+	}
+	if colors := Gradient(from, to, -1); len(colors) != 0 {
+		t.Errorf("expected an empty gradient but received %v", colors)
+	}
+	if colors := Gradient(from, to, 1); len(colors) != 1 || colors[0] != from {
+		t.Errorf("expected only the start color but received %v", colors)
+	}
+
+	colors := Gradient(from, to, 5)
+	expected := []RGBColor{0x000000, 0x000040, 0x000080, 0x0000BF, 0x0000FF}
+	if len(colors) != len(expected) {
+		t.Fatalf("expected %d colors but received %d", len(expected), len(colors))
+	}
+	for i := 0; i < len(expected); i++ {
+		if colors[i] != expected[i] {
+			t.Errorf("%d: expected %06X but received %08X", i, uint32(expected[i]), uint32(colors[i]))
+		}
+	}
+
+	// endpoints are exact and keep coverage of `from`
+	colors = Gradient(RGBColor(0x123456).Toggle(), RGBColor(0xABCDEF), 7)
+	if colors[0] != RGBColor(0x123456).Toggle() || colors[6] != RGBColor(0xABCDEF).Toggle() {
+		t.Errorf("expected exact background endpoints but received %08X and %08X", uint32(colors[0]), uint32(colors[6]))
+	}
+}
+
+func TestCGradient(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := CWrite(builder, CGradient("aé€", RGBColor(0x000000), RGBColor(0x0000FF)), TTY); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\033[38;2;0;0;0ma\033[0m\033[38;2;0;0;128mé\033[0m\033[38;2;0;0;255m€\033[0m"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+
+	builder.Reset()
+	if err := CWrite(builder, CGradient("aé€", RGBColor(0x000000), RGBColor(0x0000FF)), MonoColor); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "aé€" {
+		t.Errorf("expected text to be kept as is but received %q", builder.String())
+	}
+
+	builder.Reset()
+	if err := CWrite(builder, CGradient("", RGBColor(0x000000), RGBColor(0x0000FF)), TTY); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "" {
+		t.Errorf("expected nothing but received %q", builder.String())
+	}
+}
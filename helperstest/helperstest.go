@@ -0,0 +1,33 @@
+// Package helperstest contains assertion helpers for testing code that use `helpers` package
+package helperstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/devops-simba/helpers"
+)
+
+func describeColor(color helpers.Color) string {
+	if color == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%v(coverage: %v)", color.Code(), color.Coverage())
+}
+
+// RequireColorEqual Stop the test if `got` and `want` are not the same color
+func RequireColorEqual(t testing.TB, got, want helpers.Color) {
+	t.Helper()
+	if !helpers.ColorsEqual(got, want) {
+		t.Fatalf("Colors are not equal, got: %s, want: %s", describeColor(got), describeColor(want))
+	}
+}
+
+// RequireNoEscape Stop the test if `s` contains any ANSI escape character
+func RequireNoEscape(t testing.TB, s string) {
+	t.Helper()
+	if index := strings.IndexByte(s, '\x1b'); index != -1 {
+		t.Fatalf("Found escape character at %d in %q", index, s)
+	}
+}
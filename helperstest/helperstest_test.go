@@ -0,0 +1,58 @@
+package helperstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/devops-simba/helpers"
+)
+
+// recordingTB a `testing.TB` that record failures instead of stopping the test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (this *recordingTB) Helper() {}
+func (this *recordingTB) Fatalf(format string, args ...interface{}) {
+	this.failures = append(this.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRequireColorEqual(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	RequireColorEqual(tb, helpers.Red, helpers.RGBColor(0xFF0000))
+	RequireColorEqual(tb, nil, nil)
+	if len(tb.failures) != 0 {
+		t.Errorf("expected equal colors to pass but received %v", tb.failures)
+	}
+
+	RequireColorEqual(tb, helpers.Red, helpers.Blue)
+	RequireColorEqual(tb, helpers.Red, nil)
+	if len(tb.failures) != 2 {
+		t.Fatalf("expected 2 failures but received %v", tb.failures)
+	}
+	if !strings.Contains(tb.failures[0], "#FF0000") || !strings.Contains(tb.failures[0], "#0000FF") {
+		t.Errorf("expected both colors to be described but received %q", tb.failures[0])
+	}
+	if !strings.Contains(tb.failures[1], "<nil>") {
+		t.Errorf("expected nil color to be described but received %q", tb.failures[1])
+	}
+}
+
+func TestRequireNoEscape(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	RequireNoEscape(tb, "plain text")
+	RequireNoEscape(tb, "")
+	if len(tb.failures) != 0 {
+		t.Errorf("expected text without escape to pass but received %v", tb.failures)
+	}
+
+	RequireNoEscape(tb, "ab\033[31mc")
+	if len(tb.failures) != 1 {
+		t.Fatalf("expected 1 failure but received %v", tb.failures)
+	}
+	if !strings.Contains(tb.failures[0], "at 2") {
+		t.Errorf("expected index of the escape to be reported but received %q", tb.failures[0])
+	}
+}
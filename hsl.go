@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToHSL Convert this color to HSL, `h` is in degrees [0, 360) and `s` and `l` are in [0, 1]
+func (this RGBCode) ToHSL() (h, s, l float64) {
+	r := float64(this.Red()) / 255
+	g := float64(this.Green()) / 255
+	b := float64(this.Blue()) / 255
+
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	switch max {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	if t < 0 {
+		t += 1
+	}
+	if t > 1 {
+		t -= 1
+	}
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 1.0/2:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
+
+func clampUnit(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+
+// HSL Create an `RGBCode` from its HSL representation, `h` is in degrees and `s` and `l` are in [0, 1]
+func HSL(h, s, l float64) RGBCode {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clampUnit(s)
+	l = clampUnit(l)
+
+	toByte := func(v float64) uint8 { return uint8(math.Round(v * 255)) }
+	if s == 0 {
+		return RGB(toByte(l), toByte(l), toByte(l))
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	h /= 360
+	return RGB(toByte(hueToRGB(p, q, h+1.0/3)), toByte(hueToRGB(p, q, h)), toByte(hueToRGB(p, q, h-1.0/3)))
+}
+
+// Lighten Increase lightness of this color by `percent` percent(e.g. 20 make a 50% lightness 70%)
+func (this RGBCode) Lighten(percent float64) RGBCode {
+	h, s, l := this.ToHSL()
+	return HSL(h, s, l+percent/100)
+}
+
+// Darken Decrease lightness of this color by `percent` percent
+func (this RGBCode) Darken(percent float64) RGBCode { return this.Lighten(-percent) }
+
+// Complementary Return complementary of this color, that is this color with its hue rotated by 180 degrees
+func (this RGBCode) Complementary() RGBCode {
+	if this == NoColorCode {
+		return this
+	}
+	h, s, l := this.ToHSL()
+	return HSL(h+180, s, l)
+}
+
+// Invert Return inverse of this color, every channel of it is replaced by `255 - channel`
+func (this RGBCode) Invert() RGBCode {
+	if this == NoColorCode {
+		return this
+	}
+	return this ^ 0xFFFFFF
+}
+
+func templateNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("Expected a number but received %T", value)
+	}
+}
+
+// adjustTemplateColorLightness change lightness of a color and keep its coverage
+func adjustTemplateColorLightness(codeOrName interface{}, percent interface{}, sign float64) (Color, error) {
+	color, err := THF_Color(codeOrName)
+	if err != nil {
+		return nil, err
+	}
+	p, err := templateNumber(percent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch color.Coverage() {
+	case Foreground:
+		return RGBColor(color.Code().Lighten(sign * p)), nil
+	case Background:
+		return RGBColor(color.Code().Lighten(sign * p)).AsBackground(), nil
+	case Both:
+		return MixColors(
+			RGBColor(color.AsForeground().Code().Lighten(sign*p)),
+			RGBColor(color.AsBackground().Code().Lighten(sign*p))), nil
+	default:
+		return color, nil
+	}
+}
+
+// THF_Lighten return a color that is lighter than a color(name or code) by `percent` percent
+func THF_Lighten(codeOrName interface{}, percent interface{}) (Color, error) {
+	return adjustTemplateColorLightness(codeOrName, percent, 1)
+}
+
+// THF_Darken return a color that is darker than a color(name or code) by `percent` percent
+func THF_Darken(codeOrName interface{}, percent interface{}) (Color, error) {
+	return adjustTemplateColorLightness(codeOrName, percent, -1)
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestRGBCodeLightenDarken(t *testing.T) {
+	red := Red.Code()
+	if code := red.Darken(20); code != 0x990000 {
+		t.Errorf("expected #990000 but received %s", code)
+	}
+	if code := red.Lighten(20); code != 0xFF6666 {
+		t.Errorf("expected #FF6666 but received %s", code)
+	}
+	if code := red.Lighten(100); code != 0xFFFFFF {
+		t.Errorf("expected lightness to be clamped but received %s", code)
+	}
+	if code := red.Darken(100); code != 0x000000 {
+		t.Errorf("expected lightness to be clamped but received %s", code)
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	codes := []RGBCode{0x000000, 0xFFFFFF, 0xFF0000, 0x00FF00, 0x0000FF, 0x123456, 0x808080, 0xFFA500}
+	for i := 0; i < len(codes); i++ {
+		if code := HSL(codes[i].ToHSL()); code != codes[i] {
+			t.Errorf("expected %s but received %s", codes[i], code)
+		}
+	}
+}
+
+func TestComplementaryAndInvert(t *testing.T) {
+	complementaries := map[RGBCode]RGBCode{
+		0xFF0000: 0x00FFFF,
+		0x00FF00: 0xFF00FF,
+		0x0000FF: 0xFFFF00,
+		0x808080: 0x808080, // gray has no hue
+	}
+	for code, expected := range complementaries {
+		if result := code.Complementary(); result != expected {
+			t.Errorf("complementary of %s: expected %s but received %s", code, expected, result)
+		}
+	}
+	if h, _, _ := Red.Code().Complementary().ToHSL(); h != 180 {
+		t.Errorf("expected hue of complementary of red to be 180 but received %v", h)
+	}
+
+	if code := RGBCode(0x000000).Invert(); code != 0xFFFFFF {
+		t.Errorf("expected invert of black to be white but received %s", code)
+	}
+	if code := RGBCode(0x123456).Invert(); code != 0xEDCBA9 {
+		t.Errorf("expected #EDCBA9 but received %s", code)
+	}
+	if NoColorCode.Invert() != NoColorCode || NoColorCode.Complementary() != NoColorCode {
+		t.Errorf("expected NoColorCode to be kept")
+	}
+}
+
+func TestTHFLightenDarken(t *testing.T) {
+	SetDefaultTemplateContext(TTY)
+	defer SetDefaultTemplateContext(MonoColor)
+
+	tmpl, err := ParseTemplate("darken", `{{ WithColor (Darken "Red" 20) "x" }}|{{ WithColor (Lighten "Red" 20.0) "y" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "\033[38;2;153;0;0mx\033[0m|\033[38;2;255;102;102my\033[0m"
+	if s := executeTemplate(t, tmpl, nil); s != expected {
+		t.Errorf("expected %q but received %q", expected, s)
+	}
+
+	color, err := THF_Darken(RGBColor(0xFF0000).AsBackground(), 20)
+	if err != nil || !ColorsEqual(color, RGBColor(0x990000).AsBackground()) {
+		t.Errorf("expected a dark red background but received %v(%v)", color, err)
+	}
+	if _, err = THF_Darken("Red", "20"); err == nil {
+		t.Errorf("expected an error for a non numeric percent")
+	}
+}
@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"syscall"
+)
+
+// RetryRoundTripper a `http.RoundTripper` that retry requests that failed because of a transient error
+type RetryRoundTripper struct {
+	// Transport the transport that actually execute the requests, `http.DefaultTransport` if it is `nil`
+	Transport http.RoundTripper
+	// Policy backoff policy of the retries
+	Policy BackoffPolicy
+	// RetryNonIdempotent also retry requests that their method is not idempotent(e.g. POST)
+	RetryNonIdempotent bool
+}
+
+// NewRetryRoundTripper Create a `RetryRoundTripper` that retry idempotent requests using `policy`
+func NewRetryRoundTripper(transport http.RoundTripper, policy BackoffPolicy) *RetryRoundTripper {
+	return &RetryRoundTripper{Transport: transport, Policy: policy}
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTransientHTTPFailure Check if result of a HTTP request indicate a failure that may be fixed by retrying
+func IsTransientHTTPFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (this *RetryRoundTripper) transport() http.RoundTripper {
+	if this.Transport == nil {
+		return http.DefaultTransport
+	}
+	return this.Transport
+}
+func (this *RetryRoundTripper) canRetry(req *http.Request) bool {
+	if !this.RetryNonIdempotent && !isIdempotentMethod(req.Method) {
+		return false
+	}
+	// we can't retry a request if we can't rewind its body
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func (this *RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := this.transport()
+	if !this.canRetry(req) {
+		return transport.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := transport.RoundTrip(attemptReq)
+		if !this.Policy.CanRetry(attempt) || !IsTransientHTTPFailure(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// drain the body, so the connection can be reused
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if waitErr := this.Policy.Wait(ctx, attempt); waitErr != nil {
+			return nil, waitErr
+		}
+
+		attemptReq = req.Clone(ctx)
+		if req.Body != nil && req.Body != http.NoBody {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attemptReq.Body = body
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer create a server that respond 503 to the first `failures` requests and echo the body afterwards
+func newFlakyServer(failures int32, bodies *[]string) (*httptest.Server, *int32) {
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if bodies != nil {
+			*bodies = append(*bodies, string(body))
+		}
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	return server, calls
+}
+
+func newTestRetryClient(retryNonIdempotent bool) *http.Client {
+	rt := NewRetryRoundTripper(nil, BackoffPolicy{InitialInterval: time.Millisecond, MaxRetries: 3})
+	rt.RetryNonIdempotent = retryNonIdempotent
+	return &http.Client{Transport: rt}
+}
+
+func TestRetryRoundTripperRetryTransientFailures(t *testing.T) {
+	server, calls := newFlakyServer(2, nil)
+	defer server.Close()
+
+	resp, err := newTestRetryClient(false).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d but received %d", http.StatusOK, resp.StatusCode)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 calls but received %d", *calls)
+	}
+}
+
+func TestRetryRoundTripperGiveUpAfterMaxRetries(t *testing.T) {
+	server, calls := newFlakyServer(10, nil)
+	defer server.Close()
+
+	resp, err := newTestRetryClient(false).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected %d but received %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if *calls != 4 {
+		t.Errorf("expected 4 calls but received %d", *calls)
+	}
+}
+
+func TestRetryRoundTripperDoNotRetryNonIdempotent(t *testing.T) {
+	server, calls := newFlakyServer(2, nil)
+	defer server.Close()
+
+	resp, err := newTestRetryClient(false).Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected %d but received %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if *calls != 1 {
+		t.Errorf("expected a single call but received %d", *calls)
+	}
+}
+
+func TestRetryRoundTripperRewindBody(t *testing.T) {
+	var bodies []string
+	server, _ := newFlakyServer(2, &bodies)
+	defer server.Close()
+
+	resp, err := newTestRetryClient(true).Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	echoed, _ := ioutil.ReadAll(resp.Body)
+	if string(echoed) != "body" {
+		t.Errorf("expected %q but received %q", "body", string(echoed))
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 calls but received %d", len(bodies))
+	}
+	for i := 0; i < len(bodies); i++ {
+		if bodies[i] != "body" {
+			t.Errorf("expected %q but received %q in call %d", "body", bodies[i], i)
+		}
+	}
+}
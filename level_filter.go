@@ -0,0 +1,39 @@
+package helpers
+
+// levelFilterRule a rule of a `LevelFilter`
+type levelFilterRule struct {
+	pattern []rune
+	level   LogLevel
+}
+
+// LevelFilter a list of rules that define minimum log level of the loggers by their name. Patterns are
+// wildcards(see `WildcardMatch`) and the first matching rule win
+type LevelFilter struct {
+	rules []levelFilterRule
+}
+
+// NewLevelFilter Create an empty `LevelFilter`
+func NewLevelFilter() *LevelFilter {
+	return &LevelFilter{}
+}
+
+// Add Add a rule that set minimum level of the loggers that their name match `pattern` to `level`
+func (this *LevelFilter) Add(pattern string, level LogLevel) *LevelFilter {
+	this.rules = append(this.rules, levelFilterRule{pattern: []rune(pattern), level: level})
+	return this
+}
+
+// Match Return level of the first rule that match `source`, it is safe to call it on a nil filter
+func (this *LevelFilter) Match(source string) (LogLevel, bool) {
+	if this == nil || len(this.rules) == 0 {
+		return Debug, false
+	}
+
+	runes := []rune(source)
+	for i := 0; i < len(this.rules); i++ {
+		if matchWildcardRunes(this.rules[i].pattern, runes) {
+			return this.rules[i].level, true
+		}
+	}
+	return Debug, false
+}
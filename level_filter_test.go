@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestLevelFilterMatch(t *testing.T) {
+	filter := NewLevelFilter().Add("db/*", Error).Add("db/*", Debug).Add("http", Warn)
+	cases := []struct {
+		source   string
+		level    LogLevel
+		expected bool
+	}{
+		{"db/users", Error, true}, // first matching rule win
+		{"http", Warn, true},
+		{"http/server", Debug, false},
+		{"other", Debug, false},
+	}
+	for i := 0; i < len(cases); i++ {
+		level, ok := filter.Match(cases[i].source)
+		if level != cases[i].level || ok != cases[i].expected {
+			t.Errorf("%s: expected %v(%v) but received %v(%v)", cases[i].source,
+				cases[i].level, cases[i].expected, level, ok)
+		}
+	}
+
+	var nilFilter *LevelFilter
+	if _, ok := nilFilter.Match("db/users"); ok {
+		t.Errorf("expected nil filter to match nothing")
+	}
+}
+
+func TestResolveLevel(t *testing.T) {
+	filter := NewLevelFilter().Add("db/*", Error)
+	t.Setenv("TEST_RESOLVE_LEVEL", "warn")
+	t.Setenv("TEST_RESOLVE_LEVEL_INVALID", "loud")
+
+	cases := []struct {
+		source   string
+		envVar   string
+		expected LogLevel
+	}{
+		{"db/users", "TEST_RESOLVE_LEVEL", Warn},          // env override the filter
+		{"http", "TEST_RESOLVE_LEVEL", Warn},              // env override the default
+		{"db/users", "TEST_RESOLVE_LEVEL_INVALID", Error}, // invalid env is ignored
+		{"db/users", "TEST_RESOLVE_LEVEL_MISSING", Error}, // filter override the default
+		{"db/users", "", Error},
+		{"http", "", Info}, // default is used if nothing else match
+	}
+	for i := 0; i < len(cases); i++ {
+		if level := ResolveLevel(cases[i].source, Info, filter, cases[i].envVar); level != cases[i].expected {
+			t.Errorf("%s(%s): expected %v but received %v", cases[i].source, cases[i].envVar,
+				cases[i].expected, level)
+		}
+	}
+	if level := ResolveLevel("db/users", Info, nil, ""); level != Info {
+		t.Errorf("expected %v for a nil filter but received %v", Info, level)
+	}
+}
+
+func TestFileLogFactoryLevelFilter(t *testing.T) {
+	format := template.Must(template.New("log").Parse(`{{.Content}}`))
+	lines := runFileLogFactory(t, format, func(factory *FileLogFactory) {
+		factory.SetLevelFilter(NewLevelFilter().Add("noisy", Error))
+		noisy := factory.CreateLogger("noisy", nil, nil)
+		if level := noisy.GetMinimumLevel(); level != Error {
+			t.Errorf("expected %v but received %v", Error, level)
+		}
+		if level := factory.CreateLogger("other", nil, nil).GetMinimumLevel(); level != Debug {
+			t.Errorf("expected %v but received %v", Debug, level)
+		}
+
+		// explicit level of the logger override the filter
+		level := Debug
+		if level := factory.CreateLogger("noisy", &level, nil).GetMinimumLevel(); level != Debug {
+			t.Errorf("expected %v but received %v", Debug, level)
+		}
+		noisy.Info("dropped")
+		noisy.Error("kept")
+	})
+	if len(lines) != 1 || lines[0] != "kept" {
+		t.Errorf("expected only the error to be logged but received %q", lines)
+	}
+}
@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// levelParsingWriter an `io.Writer` that log each line that written to it using a `Logger`
+type levelParsingWriter struct {
+	lock    sync.Mutex
+	logger  Logger
+	pending []byte
+}
+
+// LevelParsingWriter Create a writer that log each line that written to it using `logger`. If a line start
+// with a bracketed level(e.g. `[ERROR] message`) it is logged with that level, otherwise it is logged as
+// `Info`. A partial line is kept until its end is written or the writer is closed
+func LevelParsingWriter(logger Logger) io.WriteCloser {
+	if logger == nil {
+		panic("Invalid argument")
+	}
+	return &levelParsingWriter{logger: logger}
+}
+
+// parseLevelPrefix Extract a leading bracketed level from `line`
+func parseLevelPrefix(line string) (LogLevel, string) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(trimmed, "[") {
+		if end := strings.IndexByte(trimmed, ']'); end != -1 {
+			unmarshaller := LogLevelUnmarshaller{}
+			if err := unmarshaller.fromString(strings.TrimSpace(trimmed[1:end])); err == nil {
+				return unmarshaller.Level, strings.TrimLeft(trimmed[end+1:], " \t")
+			}
+		}
+	}
+	return Info, line
+}
+
+func (this *levelParsingWriter) logLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+
+	level, message := parseLevelPrefix(string(line))
+	switch level {
+	case Debug:
+		this.logger.Debug(message)
+	case Warn:
+		this.logger.Warn(message)
+	case Error:
+		this.logger.Error(message)
+	case Fatal:
+		this.logger.Fatal(message)
+	default:
+		this.logger.Info(message)
+	}
+}
+func (this *levelParsingWriter) Write(b []byte) (int, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	data := b
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index == -1 {
+			break
+		}
+
+		if len(this.pending) != 0 {
+			this.pending = append(this.pending, data[:index]...)
+			this.logLine(this.pending)
+			this.pending = this.pending[:0]
+		} else {
+			this.logLine(data[:index])
+		}
+		data = data[index+1:]
+	}
+	this.pending = append(this.pending, data...)
+	return len(b), nil
+}
+
+// Close Log the remaining partial line, if there is any
+func (this *levelParsingWriter) Close() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.logLine(this.pending)
+	this.pending = nil
+	return nil
+}
@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestLevelParsingWriter(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	w := LevelParsingWriter(factory.CreateLogger("lib", nil, nil))
+
+	input := "[ERROR] failed\n" +
+		"[warn]   slow\r\n" +
+		"  [DEBUG] details\n" +
+		"plain line\n" +
+		"[unknown] kept\n" +
+		"\n" +
+		"[FATAL] par"
+	if n, err := w.Write([]byte(input)); err != nil || n != len(input) {
+		t.Fatalf("expected %d bytes to be written but received %d(%v)", len(input), n, err)
+	}
+	if records := factory.Records(); len(records) != 5 {
+		t.Fatalf("expected partial line to be kept but received %d records", len(records))
+	}
+	if _, err := w.Write([]byte("tial")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		level   LogLevel
+		message string
+	}{
+		{Error, "failed"},
+		{Warn, "slow"},
+		{Debug, "details"},
+		{Info, "plain line"},
+		{Info, "[unknown] kept"},
+		{Fatal, "partial"},
+	}
+	records := factory.Records()
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records but received %d", len(expected), len(records))
+	}
+	for i := 0; i < len(expected); i++ {
+		if records[i].Level != expected[i].level || records[i].Message() != expected[i].message {
+			t.Errorf("record %d: expected %v %q but received %v %q", i,
+				expected[i].level, expected[i].message, records[i].Level, records[i].Message())
+		}
+		if records[i].LogSource != "lib" {
+			t.Errorf("record %d: expected %q but received %q", i, "lib", records[i].LogSource)
+		}
+	}
+}
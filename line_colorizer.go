@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// LineColorizer copy lines of a reader to a writer and color each line using a classifier
+type LineColorizer struct {
+	// Context context that used to write the lines, default context of the writer is used if it is nil
+	Context ColorContext
+	// Classifier return color of a line(without its line ending), it may return nil or `NoColor` to
+	// write the line without any color
+	Classifier func(line string) Color
+}
+
+// NewLineColorizer Create a `LineColorizer`
+func NewLineColorizer(context ColorContext, classifier func(line string) Color) *LineColorizer {
+	if classifier == nil {
+		panic("Invalid argument")
+	}
+	return &LineColorizer{Context: context, Classifier: classifier}
+}
+
+// Colorize Read lines of `r` until EOF and write them as colored lines to `w`, lines may be split
+// across multiple reads
+func (this *LineColorizer) Colorize(w io.Writer, r io.Reader) error {
+	context := this.Context
+	if context == nil {
+		context = GetDefaultContext(w)
+	}
+
+	cw := NewColoredWriter(context, w)
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			eol := ""
+			if strings.HasSuffix(line, "\n") {
+				line = line[:len(line)-1]
+				eol = "\n"
+				if strings.HasSuffix(line, "\r") {
+					line = line[:len(line)-1]
+					eol = "\r\n"
+				}
+			}
+
+			if err := cw.WriteContent(CContent(this.Classifier(line), line)); err != nil {
+				return err
+			}
+			if eol != "" {
+				if err := cw.WriteString(eol); err != nil {
+					return err
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLineColorizer(t *testing.T) {
+	colorizer := NewLineColorizer(TTY, func(line string) Color {
+		if strings.Contains(line, "ERROR") {
+			return Red
+		}
+		return nil
+	})
+
+	input := "starting\nERROR failed\r\ndone\nERROR partial"
+	// a one byte reader split every line across multiple reads
+	builder := &strings.Builder{}
+	if err := colorizer.Colorize(builder, iotest.OneByteReader(strings.NewReader(input))); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "starting\n" +
+		"\033[38;2;255;0;0mERROR failed\033[0m\r\n" +
+		"done\n" +
+		"\033[38;2;255;0;0mERROR partial\033[0m"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestLineColorizerReadError(t *testing.T) {
+	colorizer := NewLineColorizer(MonoColor, func(line string) Color { return Red })
+	builder := &strings.Builder{}
+	err := colorizer.Colorize(builder, iotest.TimeoutReader(strings.NewReader("a\nb")))
+	if err != iotest.ErrTimeout {
+		t.Errorf("expected %v but received %v", iotest.ErrTimeout, err)
+	}
+}
@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// LogfmtFormat format of the `FileLogFactory` that write records in logfmt format
+	LogfmtFormat = `time={{.LogTime.Format "2006-01-02T15:04:05.000Z07:00"}} {{Logfmt .}}`
+)
+
+var LogfmtKeyColor Color = Cyan
+
+// NewLogfmtFileLogFactory Create a `FileLogFactory` that write records in logfmt format
+func NewLogfmtFileLogFactory(
+	output *os.File,
+	minimumLogLevel LogLevel,
+	verbosityLevel int,
+	mustCloseOutput bool) *FileLogFactory {
+	format, err := ParseTemplate("logfmt", LogfmtFormat)
+	if err != nil {
+		panic(err)
+	}
+	return NewFileLogFactory(format, output, minimumLogLevel, verbosityLevel, mustCloseOutput)
+}
+
+// LogfmtValue Return `value` as a logfmt value, quoting it if it is empty or contain spaces, `=` or `"`
+func LogfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, ch := range value {
+		if ch == '=' || ch == '"' || ch == '\\' || unicode.IsSpace(ch) || !unicode.IsPrint(ch) {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// RenderLogfmt Write `rec` to `w` as `level=info source=x msg="..." field=value`, fields are sorted by
+// their name and keys are colored with `LogfmtKeyColor`
+func RenderLogfmt(w io.Writer, context ColorContext, rec *LogRecord) error {
+	if context == nil {
+		context = GetDefaultContext(w)
+	}
+
+	cw := NewColoredWriter(context, w)
+	if err := writeLogfmtPair(cw, "level", strings.ToLower(rec.Level.Format("n")), true); err != nil {
+		return err
+	}
+	if err := writeLogfmtPair(cw, "source", rec.LogSource, false); err != nil {
+		return err
+	}
+	if err := writeLogfmtPair(cw, "msg", rec.Message(), false); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(rec.Fields))
+	for name := range rec.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i := 0; i < len(names); i++ {
+		value := fmt.Sprint(rec.Fields[names[i]])
+		if err := writeLogfmtPair(cw, names[i], value, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLogfmtPair(cw *ColoredWriter, key, value string, first bool) error {
+	if !first {
+		if err := cw.WriteString(" "); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteContent(CContent(LogfmtKeyColor, key)); err != nil {
+		return err
+	}
+	return cw.WriteString("=" + LogfmtValue(value))
+}
+
+// THF_Logfmt render a `LogRecord` in logfmt format using context of the record
+func THF_Logfmt(rec *LogRecord) (string, error) {
+	context := rec.GetContext()
+	if context == nil {
+		context = MonoColor
+	}
+
+	builder := &strings.Builder{}
+	if err := RenderLogfmt(builder, context, rec); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestLogfmtValue(t *testing.T) {
+	cases := map[string]string{
+		"":          `""`,
+		"plain":     "plain",
+		"two words": `"two words"`,
+		"a=b":       `"a=b"`,
+		`say "hi"`:  `"say \"hi\""`,
+		"tab\there": `"tab\there"`,
+		`back\path`: `"back\\path"`,
+		"日本":        "日本",
+	}
+	for value, expected := range cases {
+		if result := LogfmtValue(value); result != expected {
+			t.Errorf("LogfmtValue(%q): expected %s but received %s", value, expected, result)
+		}
+	}
+}
+
+func TestRenderLogfmt(t *testing.T) {
+	rec := &LogRecord{
+		Level:     Warn,
+		LogSource: "services/api",
+		Content:   CreateFormatContent("request failed: %s", "timeout"),
+		Fields:    map[string]interface{}{"zone": "eu west", "attempt": 3, "id": "a=1"},
+	}
+
+	builder := &strings.Builder{}
+	if err := RenderLogfmt(builder, MonoColor, rec); err != nil {
+		t.Fatal(err)
+	}
+	expected := `level=warn source=services/api msg="request failed: timeout" attempt=3 id="a=1" zone="eu west"`
+	if builder.String() != expected {
+		t.Errorf("expected %s but received %s", expected, builder.String())
+	}
+
+	builder.Reset()
+	if err := RenderLogfmt(builder, TTY, rec); err != nil {
+		t.Fatal(err)
+	}
+	key := "\033[" + LogfmtKeyColor.TerminalColorName().Foreground + "mlevel\033[0m=warn"
+	if !strings.HasPrefix(builder.String(), key) || StripEscapes(builder.String()) != expected {
+		t.Errorf("expected colored keys but received %q", builder.String())
+	}
+}
+
+func TestLogfmtFileLogFactory(t *testing.T) {
+	lines := runFileLogFactory(t, template.Must(ParseTemplate("logfmt", LogfmtFormat)), func(factory *FileLogFactory) {
+		factory.CreateLogger("test", nil, nil).Info("hello world")
+	})
+	if len(lines) != 1 {
+		t.Fatalf("expected a single line but received %q", lines)
+	}
+	prefix, rest, _ := strings.Cut(lines[0], " ")
+	if _, err := time.Parse("time=2006-01-02T15:04:05.000Z07:00", prefix); err != nil {
+		t.Errorf("expected a time but received %q(%v)", prefix, err)
+	}
+	if rest != `level=info source=test msg="hello world"` {
+		t.Errorf("unexpected record %q", rest)
+	}
+}
@@ -1,11 +1,17 @@
 package helpers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 )
@@ -154,8 +160,12 @@ type LogRecord struct {
 	LogSource string
 	LogTime   time.Time
 	Content   interface{}
-	context   ColorContext
-	colorMap  *ColorNameMap
+	// Ctx context that attached to the logger using `WithContext`, it is only used for propagating values
+	Ctx context.Context
+	// Fields values that extracted from `Ctx` using registered `LogContextExtractor`s
+	Fields   map[string]interface{}
+	context  ColorContext
+	colorMap *ColorNameMap
 }
 
 // Support for colored templating
@@ -167,6 +177,60 @@ func (this *LogRecord) GetDefaultColor() Color {
 	return code.ToColor()
 }
 
+// Message render content of this record as a plain text
+func (this *LogRecord) Message() string {
+	builder := &strings.Builder{}
+	CWrite(builder, this.Content, MonoColor)
+	return builder.String()
+}
+
+// LogContextExtractor extract a value from the context of a logger to be added to fields of its records
+type LogContextExtractor func(ctx context.Context) (value interface{}, ok bool)
+
+var (
+	logContextExtractors     = map[string]LogContextExtractor{}
+	logContextExtractorsLock = sync.RWMutex{}
+)
+
+// RegisterLogContextExtractor register an extractor that fill field `name` of the records that have a context
+func RegisterLogContextExtractor(name string, extractor LogContextExtractor) {
+	if name == "" || extractor == nil {
+		panic("Invalid argument")
+	}
+
+	logContextExtractorsLock.Lock()
+	defer logContextExtractorsLock.Unlock()
+	logContextExtractors[name] = extractor
+}
+
+// LogContextValueExtractor Create a `LogContextExtractor` that read `key` from the context
+func LogContextValueExtractor(key interface{}) LogContextExtractor {
+	return func(ctx context.Context) (interface{}, bool) {
+		value := ctx.Value(key)
+		return value, value != nil
+	}
+}
+
+func extractLogFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	logContextExtractorsLock.RLock()
+	defer logContextExtractorsLock.RUnlock()
+
+	var result map[string]interface{}
+	for name, extractor := range logContextExtractors {
+		if value, ok := extractor(ctx); ok {
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			result[name] = value
+		}
+	}
+	return result
+}
+
 type LogFactory interface {
 	io.Closer
 	CreateLogger(name string, level *LogLevel, verbosityLevel *int) Logger
@@ -178,6 +242,8 @@ type Logger interface {
 	GetVerbosityLevel() int
 
 	CreateLogger(name string, level *LogLevel, verbosityLevel *int) Logger
+	// WithContext Create a logger that attach `ctx` to its records. `ctx` is only used for propagating values
+	WithContext(ctx context.Context) Logger
 
 	V(verbosityLevel int) bool
 	IsEnabled(level LogLevel) bool
@@ -195,6 +261,14 @@ type Logger interface {
 
 	Verbose(verbosityLevel int, message interface{})
 	Verbosef(verbosityLevel int, format string, args ...interface{})
+
+	// Lazy versions only call `fn` to create the message if the level is enabled
+	DebugLazy(fn func() interface{})
+	InfoLazy(fn func() interface{})
+	WarnLazy(fn func() interface{})
+	ErrorLazy(fn func() interface{})
+	FatalLazy(fn func() interface{})
+	VerboseLazy(verbosityLevel int, fn func() interface{})
 }
 
 const (
@@ -214,6 +288,7 @@ type NullLoggerT bool
 func (this NullLoggerT) CreateLogger(name string, level *LogLevel, verbosityLevel *int) Logger {
 	return this
 }
+func (this NullLoggerT) WithContext(ctx context.Context) Logger                          { return this }
 func (this NullLoggerT) GetName() string                                                 { return "Null" }
 func (this NullLoggerT) GetLogFactory() LogFactory                                       { return NullLoggerFactory }
 func (this NullLoggerT) GetMinimumLevel() LogLevel                                       { return Fatal }
@@ -233,6 +308,13 @@ func (this NullLoggerT) Fatalf(format string, args ...interface{})
 func (this NullLoggerT) Verbose(verbosityLevel int, message interface{})                 {}
 func (this NullLoggerT) Verbosef(verbosityLevel int, format string, args ...interface{}) {}
 
+func (this NullLoggerT) DebugLazy(fn func() interface{})                       {}
+func (this NullLoggerT) InfoLazy(fn func() interface{})                        {}
+func (this NullLoggerT) WarnLazy(fn func() interface{})                        {}
+func (this NullLoggerT) ErrorLazy(fn func() interface{})                       {}
+func (this NullLoggerT) FatalLazy(fn func() interface{})                       {}
+func (this NullLoggerT) VerboseLazy(verbosityLevel int, fn func() interface{}) {}
+
 type FileLogFactory struct {
 	name           string
 	dispatcher     chan *LogRecord
@@ -243,6 +325,9 @@ type FileLogFactory struct {
 	minimumLevel   LogLevel
 	verbosityLevel int
 	colorMap       *ColorNameMap
+	redactedFields atomic.Pointer[map[string]struct{}]
+	levelFilter    *LevelFilter
+	levelEnvName   string
 }
 
 // NewFileLogFactory Create a a ``FileLogFactory``
@@ -273,8 +358,125 @@ func NewFileLogFactory(
 	return result
 }
 
+const (
+	// DefaultLogTimeLayout layout of the time in the default log template
+	DefaultLogTimeLayout = "2006-01-02 15:04:05.000"
+	// defaultLogFormat format of the default log template, `%s` will be replaced with the quoted time layout
+	defaultLogFormat        = `{{.LogTime.Format %s}} {{.Level.Format "short"}} {{.LogSource}}: {{.Content}}`
+	defaultColoredLogFormat = `{{.LogTime.Format %s}} {{WithColorC . "" (.Level.Format "short")}} {{.LogSource}}: {{.Content}}`
+)
+
+// DefaultLogTemplate Create a template that render records as `time level source: message`, if `colored` is
+// true level of the record will be colored using color of the level
+func DefaultLogTemplate(colored bool) *template.Template {
+	return DefaultLogTemplateWithTimeLayout(colored, DefaultLogTimeLayout)
+}
+
+// DefaultLogTemplateWithTimeLayout Create a default log template that format time of the records using `timeLayout`
+func DefaultLogTemplateWithTimeLayout(colored bool, timeLayout string) *template.Template {
+	format := defaultLogFormat
+	if colored {
+		format = defaultColoredLogFormat
+	}
+
+	result, err := ParseTemplate("log", fmt.Sprintf(format, strconv.Quote(timeLayout)))
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// OpenLogFile Open a file for writing logs, existing content of the file is kept if `append` is true and
+// cleared otherwise. The file is created if it does not exist
+func OpenLogFile(path string, append bool) (*os.File, error) {
+	flags := os.O_CREATE | os.O_WRONLY
+	if append {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	return os.OpenFile(path, flags, 0644)
+}
+
+const (
+	LogLevelEnvName     = "LOG_LEVEL"
+	LogVerbosityEnvName = "LOG_VERBOSITY"
+)
+
+// ReadLogLevelEnv Read a `LogLevel`(name or number) from an environment variable or a default value if it is
+// not set or it is not valid
+func ReadLogLevelEnv(envName string, defaultValue LogLevel) LogLevel {
+	if level, ok := lookupLogLevelEnv(envName); ok {
+		return level
+	}
+	return defaultValue
+}
+func lookupLogLevelEnv(envName string) (LogLevel, bool) {
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return Debug, false
+	}
+
+	unmarshaller := LogLevelUnmarshaller{}
+	if err := unmarshaller.fromString(value); err == nil {
+		return unmarshaller.Level, true
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if err = unmarshaller.fromInt(n); err == nil {
+			return unmarshaller.Level, true
+		}
+	}
+	return Debug, false
+}
+
+// ResolveLevel Return effective minimum level of the logger of `source`. A valid level in `envVar`
+// environment variable has the highest priority, then the first matching rule of `filter` and at last
+// `factoryDefault`. `filter` may be nil and `envVar` may be empty
+func ResolveLevel(source string, factoryDefault LogLevel, filter *LevelFilter, envVar string) LogLevel {
+	if envVar != "" {
+		if level, ok := lookupLogLevelEnv(envVar); ok {
+			return level
+		}
+	}
+	if level, ok := filter.Match(source); ok {
+		return level
+	}
+	return factoryDefault
+}
+
+// NewFileLogFactoryFromEnv Create a ``FileLogFactory`` that read its minimum level and verbosity from
+// `LOG_LEVEL` and `LOG_VERBOSITY` environment variables, falling back to provided defaults
+func NewFileLogFactoryFromEnv(
+	format *template.Template,
+	output *os.File,
+	defaultLogLevel LogLevel,
+	defaultVerbosityLevel int,
+	mustCloseOutput bool) *FileLogFactory {
+	result := NewFileLogFactory(
+		format,
+		output,
+		ReadLogLevelEnv(LogLevelEnvName, defaultLogLevel),
+		ReadEnvInt(LogVerbosityEnvName, defaultVerbosityLevel),
+		mustCloseOutput)
+	// environment must also override level filters of the factory
+	result.levelEnvName = LogLevelEnvName
+	return result
+}
+
+func (this *FileLogFactory) getColorMap() *ColorNameMap { return this.colorMap }
+func (this *FileLogFactory) resolveLevel(source string, inherited LogLevel) LogLevel {
+	return ResolveLevel(source, inherited, this.levelFilter, this.levelEnvName)
+}
+func (this *FileLogFactory) dispatchRecord(rec *LogRecord) {
+	this.dispatcher <- rec
+}
 func (this *FileLogFactory) dispatch() {
 	context := GetDefaultContext(this.output)
+	if context == MonoColor {
+		// output is not a terminal(e.g. a log file), so write records as plain text
+		context = Strip
+	}
+	buffer := &bytes.Buffer{}
 	for {
 		rec := <-this.dispatcher
 		if rec == nil {
@@ -282,25 +484,90 @@ func (this *FileLogFactory) dispatch() {
 		}
 
 		rec.context = context
+		this.redactRecord(rec)
 		if _, ok := rec.Content.(ColoredContent); ok {
 			rec.Content = BindContentToContext(context, rec.Content)
 		}
 
-		err := this.format.Execute(this.output, rec)
-		this.output.Write(EOL)
-		if err != nil {
-			fmt.Printf("LOG FAILED: %v\n", err)
+		// render the record in a buffer, so a failed template never write a partial line to the output
+		buffer.Reset()
+		if err := this.format.Execute(buffer, rec); err != nil {
+			buffer.Reset()
+			fmt.Fprintf(buffer, "LOG FORMAT FAILED(%v): %s %s %s: %s",
+				err, rec.LogTime.Format(time.RFC3339), rec.Level.Format("short"), rec.LogSource, rec.Message())
 		}
+		buffer.Write(EOL)
+		this.output.Write(buffer.Bytes())
 	}
 	close(this.stopped)
 }
+
+// Redact Replace value of the fields with specified names in the content and fields of the records with
+// `RedactedValue`. Fields of structs that tagged with `log:"secret"` are always redacted, so this only add more
+// secret names. It may be called while loggers of this factory are in use
+func (this *FileLogFactory) Redact(fields ...string) *FileLogFactory {
+	// published set is never modified, so dispatcher can read it without a lock
+	for {
+		old := this.redactedFields.Load()
+		redactedFields := newRedactedFieldSet(fields)
+		if old != nil {
+			for name := range *old {
+				redactedFields[name] = struct{}{}
+			}
+		}
+		if this.redactedFields.CompareAndSwap(old, &redactedFields) {
+			return this
+		}
+	}
+}
+
+func (this *FileLogFactory) redactRecord(rec *LogRecord) {
+	// tagged fields are redacted even if no field name is registered
+	var redactedFields map[string]struct{}
+	if redactedFieldsPtr := this.redactedFields.Load(); redactedFieldsPtr != nil {
+		redactedFields = *redactedFieldsPtr
+	}
+
+	if format, ok := rec.Content.(FormatContent); ok {
+		redacted := make(FormatContent, len(format))
+		for i := 0; i < len(format); i++ {
+			redacted[i] = format[i]
+			if format[i].FormatString != "" && !format[i].NoArg {
+				redacted[i].Arg, _ = redactValue(reflect.ValueOf(format[i].Arg), redactedFields, 0)
+			}
+		}
+		rec.Content = redacted
+	} else {
+		rec.Content, _ = redactValue(reflect.ValueOf(rec.Content), redactedFields, 0)
+	}
+
+	if len(rec.Fields) != 0 && len(redactedFields) != 0 {
+		fields := make(map[string]interface{}, len(rec.Fields))
+		for name, value := range rec.Fields {
+			if _, isSecret := redactedFields[strings.ToLower(name)]; isSecret {
+				value = RedactedValue
+			}
+			fields[name] = value
+		}
+		rec.Fields = fields
+	}
+}
+
+// SetLevelFilter Set filter that define minimum level of the loggers by their name, it is only used when
+// minimum level of the logger is not specified in `CreateLogger`.
+// This must be called before creating the loggers of this factory
+func (this *FileLogFactory) SetLevelFilter(filter *LevelFilter) *FileLogFactory {
+	this.levelFilter = filter
+	return this
+}
 func (this *FileLogFactory) SetColor(level LogLevel, color Color) *FileLogFactory {
 	this.colorMap.AddName("log:"+level.Format("letter"), color.Code())
 	return this
 }
 func (this *FileLogFactory) CreateLogger(name string, minimumLogLevel *LogLevel, verbosityLevel *int) Logger {
 	if minimumLogLevel == nil {
-		minimumLogLevel = &this.minimumLevel
+		level := this.resolveLevel(name, this.minimumLevel)
+		minimumLogLevel = &level
 	}
 	if verbosityLevel == nil {
 		verbosityLevel = &this.verbosityLevel
@@ -321,11 +588,21 @@ func (this *FileLogFactory) Close() error {
 	return nil
 }
 
+// logRecordSink a `LogFactory` that receive records of the loggers that it created
+type logRecordSink interface {
+	LogFactory
+	getColorMap() *ColorNameMap
+	dispatchRecord(rec *LogRecord)
+	// resolveLevel Return minimum level of a logger that its level is not specified
+	resolveLevel(source string, inherited LogLevel) LogLevel
+}
+
 type FileLogger struct {
-	factory        *FileLogFactory
+	factory        logRecordSink
 	name           string
 	minimumLevel   LogLevel
 	verbosityLevel int
+	ctx            context.Context
 }
 
 func (this FileLogger) doLog(level LogLevel, message interface{}) {
@@ -334,10 +611,12 @@ func (this FileLogger) doLog(level LogLevel, message interface{}) {
 		LogSource: this.name,
 		LogTime:   time.Now(),
 		Content:   message,
-		colorMap:  this.factory.colorMap,
+		Ctx:       this.ctx,
+		Fields:    extractLogFields(this.ctx),
+		colorMap:  this.factory.getColorMap(),
 	}
 
-	this.factory.dispatcher <- rec
+	this.factory.dispatchRecord(rec)
 }
 func (this FileLogger) doLogf(level LogLevel, format string, args ...interface{}) {
 	this.doLog(level, CreateFormatContent(format, args...))
@@ -359,19 +638,26 @@ func (this FileLogger) GetLogFactory() LogFactory { return this.factory }
 func (this FileLogger) GetMinimumLevel() LogLevel { return this.minimumLevel }
 func (this FileLogger) GetVerbosityLevel() int    { return this.verbosityLevel }
 func (this FileLogger) CreateLogger(name string, minimumLogLevel *LogLevel, verbosityLevel *int) Logger {
+	name = this.name + "." + name
 	if minimumLogLevel == nil {
-		minimumLogLevel = &this.minimumLevel
+		level := this.factory.resolveLevel(name, this.minimumLevel)
+		minimumLogLevel = &level
 	}
 	if verbosityLevel == nil {
 		verbosityLevel = &this.verbosityLevel
 	}
 	return FileLogger{
 		factory:        this.factory,
-		name:           this.name + "." + name,
+		name:           name,
 		minimumLevel:   *minimumLogLevel,
 		verbosityLevel: *verbosityLevel,
+		ctx:            this.ctx,
 	}
 }
+func (this FileLogger) WithContext(ctx context.Context) Logger {
+	this.ctx = ctx
+	return this
+}
 func (this FileLogger) V(verbosityLevel int) bool                 { return verbosityLevel >= this.verbosityLevel }
 func (this FileLogger) IsEnabled(level LogLevel) bool             { return level >= this.minimumLevel }
 func (this FileLogger) Debug(message interface{})                 { this.log(Debug, message) }
@@ -385,12 +671,29 @@ func (this FileLogger) Errorf(format string, args ...interface{}) { this.logf(Er
 func (this FileLogger) Fatal(message interface{})                 { this.log(Fatal, message) }
 func (this FileLogger) Fatalf(format string, args ...interface{}) { this.logf(Fatal, format, args...) }
 func (this FileLogger) Verbose(verbosityLevel int, message interface{}) {
-	if verbosityLevel <= this.verbosityLevel {
+	// verbose messages are logged as `Info`, so there is no need to dispatch them if `Info` is disabled
+	if verbosityLevel <= this.verbosityLevel && this.IsEnabled(Info) {
 		this.doLog(Info, message)
 	}
 }
 func (this FileLogger) Verbosef(verbosityLevel int, format string, args ...interface{}) {
-	if verbosityLevel <= this.verbosityLevel {
+	if verbosityLevel <= this.verbosityLevel && this.IsEnabled(Info) {
 		this.doLogf(Info, format, args...)
 	}
 }
+func (this FileLogger) logLazy(level LogLevel, fn func() interface{}) {
+	if level >= this.minimumLevel {
+		this.doLog(level, fn())
+	}
+}
+
+func (this FileLogger) DebugLazy(fn func() interface{}) { this.logLazy(Debug, fn) }
+func (this FileLogger) InfoLazy(fn func() interface{})  { this.logLazy(Info, fn) }
+func (this FileLogger) WarnLazy(fn func() interface{})  { this.logLazy(Warn, fn) }
+func (this FileLogger) ErrorLazy(fn func() interface{}) { this.logLazy(Error, fn) }
+func (this FileLogger) FatalLazy(fn func() interface{}) { this.logLazy(Fatal, fn) }
+func (this FileLogger) VerboseLazy(verbosityLevel int, fn func() interface{}) {
+	if verbosityLevel <= this.verbosityLevel && this.IsEnabled(Info) {
+		this.doLog(Info, fn())
+	}
+}
@@ -0,0 +1,358 @@
+package helpers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestVerboseRespectsMinimumLevel(t *testing.T) {
+	factory := NewMemoryLogFactory(Warn, 5)
+	logger := factory.CreateLogger("test", nil, nil)
+
+	logger.Verbose(1, "verbose")
+	logger.Verbosef(1, "verbose %d", 2)
+	if records := factory.Records(); len(records) != 0 {
+		t.Errorf("expected no record but received %d", len(records))
+	}
+
+	logger.Warn("warning")
+	if records := factory.Records(); len(records) != 1 || records[0].Level != Warn {
+		t.Errorf("expected a single warning record but received %v", records)
+	}
+}
+
+func TestVerboseRespectsVerbosityLevel(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 2)
+	logger := factory.CreateLogger("test", nil, nil)
+
+	logger.Verbose(3, "too verbose")
+	logger.Verbosef(2, "verbose %d", 2)
+	records := factory.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected a single record but received %d", len(records))
+	}
+	if records[0].Level != Info || records[0].Message() != "verbose 2" {
+		t.Errorf("unexpected record: %v %q", records[0].Level, records[0].Message())
+	}
+}
+
+type testTraceIDKey struct{}
+
+func TestLoggerWithContextExtractFields(t *testing.T) {
+	RegisterLogContextExtractor("test.traceId", LogContextValueExtractor(testTraceIDKey{}))
+	defer func() {
+		logContextExtractorsLock.Lock()
+		delete(logContextExtractors, "test.traceId")
+		logContextExtractorsLock.Unlock()
+	}()
+
+	factory := NewMemoryLogFactory(Debug, 0)
+	ctx := context.WithValue(context.Background(), testTraceIDKey{}, "trace-1")
+	logger := factory.CreateLogger("test", nil, nil).WithContext(ctx)
+
+	logger.Info("with context")
+	logger.CreateLogger("child", nil, nil).Info("child with context")
+	factory.CreateLogger("test", nil, nil).Info("without context")
+
+	records := factory.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records but received %d", len(records))
+	}
+	for i := 0; i < 2; i++ {
+		if records[i].Ctx != ctx {
+			t.Errorf("expected context to be attached to %q", records[i].Message())
+		}
+		if value := records[i].Fields["test.traceId"]; value != "trace-1" {
+			t.Errorf("expected %q but received %v", "trace-1", value)
+		}
+	}
+	if records[2].Ctx != nil || records[2].Fields != nil {
+		t.Errorf("expected no context and fields but received %v", records[2].Fields)
+	}
+}
+
+// runFileLogFactory log using a `FileLogFactory` that write to a temporary file and return lines of that file
+func runFileLogFactory(t *testing.T, format *template.Template, fn func(factory *FileLogFactory)) []string {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "log.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := NewFileLogFactory(format, file, Debug, 0, true)
+	fn(factory)
+	if err = factory.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestFileLogFactoryTemplateFailure(t *testing.T) {
+	format := template.Must(template.New("log").Parse(
+		`prefix {{if eq .LogSource "bad"}}{{.Missing}}{{end}}{{.Content}}`))
+	lines := runFileLogFactory(t, format, func(factory *FileLogFactory) {
+		factory.CreateLogger("good", nil, nil).Info("first")
+		factory.CreateLogger("bad", nil, nil).Info("broken")
+		factory.CreateLogger("good", nil, nil).Info("last")
+	})
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but received %q", lines)
+	}
+	if lines[0] != "prefix first" || lines[2] != "prefix last" {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], "LOG FORMAT FAILED(") || !strings.HasSuffix(lines[1], " INF bad: broken") {
+		t.Errorf("expected a fallback line but received %q", lines[1])
+	}
+	if strings.Contains(lines[1], "prefix") {
+		t.Errorf("expected no partial output but received %q", lines[1])
+	}
+}
+
+func TestNewFileLogFactoryFromEnv(t *testing.T) {
+	cases := []struct {
+		level     string
+		verbosity string
+		expected  LogLevel
+		verbose   int
+	}{
+		{"Error", "7", Error, 7},
+		{"debug", "0", Debug, 0},
+		{"3", "2", Error, 2},
+		{"not-a-level", "not-a-number", Warn, 4},
+		{"", "", Warn, 4},
+	}
+	for i := 0; i < len(cases); i++ {
+		t.Setenv(LogLevelEnvName, cases[i].level)
+		t.Setenv(LogVerbosityEnvName, cases[i].verbosity)
+
+		factory := NewFileLogFactoryFromEnv(DefaultLogTemplate(false), os.Stderr, Warn, 4, false)
+		if factory.minimumLevel != cases[i].expected || factory.verbosityLevel != cases[i].verbose {
+			t.Errorf("LOG_LEVEL=%q, LOG_VERBOSITY=%q: expected %v, %d but received %v, %d",
+				cases[i].level, cases[i].verbosity, cases[i].expected, cases[i].verbose,
+				factory.minimumLevel, factory.verbosityLevel)
+		}
+		factory.Close()
+	}
+}
+
+func TestReadEnvInt(t *testing.T) {
+	t.Setenv("TEST_READ_ENV_INT", "42")
+	if n := ReadEnvInt("TEST_READ_ENV_INT", 1); n != 42 {
+		t.Errorf("expected 42 but received %d", n)
+	}
+	t.Setenv("TEST_READ_ENV_INT", "4x")
+	if n := ReadEnvInt("TEST_READ_ENV_INT", 1); n != 1 {
+		t.Errorf("expected 1 but received %d", n)
+	}
+	if n := ReadEnvInt("TEST_READ_ENV_INT_NOT_SET", 1); n != 1 {
+		t.Errorf("expected 1 but received %d", n)
+	}
+}
+
+func TestLazyLoggingSkipDisabledLevels(t *testing.T) {
+	factory := NewMemoryLogFactory(Warn, 2)
+	logger := factory.CreateLogger("test", nil, nil)
+
+	calls := 0
+	thunk := func(message string) func() interface{} {
+		return func() interface{} {
+			calls++
+			return message
+		}
+	}
+	logger.DebugLazy(thunk("debug"))
+	logger.InfoLazy(thunk("info"))
+	logger.VerboseLazy(1, thunk("verbose"))
+	if calls != 0 {
+		t.Errorf("expected no thunk to be called but %d called", calls)
+	}
+
+	logger.WarnLazy(thunk("warn"))
+	logger.ErrorLazy(thunk("error"))
+	logger.FatalLazy(thunk("fatal"))
+	if calls != 3 {
+		t.Errorf("expected 3 thunks to be called but %d called", calls)
+	}
+	records := factory.Records()
+	if len(records) != 3 || records[0].Message() != "warn" || records[2].Level != Fatal {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
+
+func TestVerboseLazyRespectsVerbosityLevel(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 2)
+	logger := factory.CreateLogger("test", nil, nil)
+
+	called := false
+	logger.VerboseLazy(3, func() interface{} {
+		called = true
+		return "too verbose"
+	})
+	if called {
+		t.Errorf("expected thunk not to be called above the verbosity level")
+	}
+	logger.VerboseLazy(2, func() interface{} { return "verbose" })
+	if records := factory.Records(); len(records) != 1 || records[0].Message() != "verbose" {
+		t.Errorf("expected a single verbose record but received %v", records)
+	}
+
+	NullLogger.DebugLazy(func() interface{} {
+		t.Errorf("expected null logger to never call the thunk")
+		return nil
+	})
+}
+
+func TestFileLogFactoryRedact(t *testing.T) {
+	format := template.Must(template.New("log").Parse(`{{.Content}}`))
+	creds := &testCredentials{User: "user", Password: "pass", Token: "token"}
+	lines := runFileLogFactory(t, format, func(factory *FileLogFactory) {
+		logger := factory.CreateLogger("test", nil, nil)
+		logger.Info(creds)
+		factory.Redact("token")
+		logger.Info(creds)
+		logger.Infof("login %v", creds)
+	})
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but received %q", lines)
+	}
+	// tagged fields are redacted before any field name is registered
+	if strings.Contains(lines[0], "pass") || !strings.Contains(lines[0], "token") ||
+		!strings.Contains(lines[0], RedactedValue) {
+		t.Errorf("expected only tagged secrets to be redacted but received %q", lines[0])
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.Contains(lines[i], "pass") || strings.Contains(lines[i], "token") ||
+			!strings.Contains(lines[i], "user") || !strings.Contains(lines[i], RedactedValue) {
+			t.Errorf("expected secrets to be redacted but received %q", lines[i])
+		}
+	}
+	if creds.Password != "pass" {
+		t.Errorf("expected logged value to be unchanged")
+	}
+}
+
+func TestFileLogFactoryRedactConcurrently(t *testing.T) {
+	format := template.Must(template.New("log").Parse(`{{.Content}}`))
+	runFileLogFactory(t, format, func(factory *FileLogFactory) {
+		logger := factory.CreateLogger("test", nil, nil)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				factory.Redact("field" + strings.Repeat("x", i))
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			logger.Info(map[string]string{"field": "value"})
+		}
+		<-done
+	})
+}
+
+func TestOpenLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write := func(append bool, content string) {
+		t.Helper()
+		file, err := OpenLogFile(path, append)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = file.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		if err = file.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	// file is created if it does not exist
+	write(true, "first\n")
+	write(true, "second\n")
+	if s := read(); s != "first\nsecond\n" {
+		t.Errorf("expected append to keep existing content but received %q", s)
+	}
+
+	write(false, "third\n")
+	if s := read(); s != "third\n" {
+		t.Errorf("expected truncate to clear existing content but received %q", s)
+	}
+}
+
+func TestDefaultLogTemplate(t *testing.T) {
+	rec := &LogRecord{
+		Level:     Error,
+		LogSource: "db",
+		LogTime:   time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC),
+		Content:   "connection lost",
+		context:   TTY,
+		colorMap:  NewColorNameMap(nil).AddName("log:E", Red.Code()),
+	}
+
+	builder := &strings.Builder{}
+	if err := DefaultLogTemplate(false).Execute(builder, rec); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2021-03-04 05:06:07.008 ERR db: connection lost"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+
+	builder.Reset()
+	if err := DefaultLogTemplate(true).Execute(builder, rec); err != nil {
+		t.Fatal(err)
+	}
+	expected = "2021-03-04 05:06:07.008 \033[38;2;255;0;0mERR\033[0m db: connection lost"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+
+	builder.Reset()
+	if err := DefaultLogTemplateWithTimeLayout(false, time.Kitchen).Execute(builder, rec); err != nil {
+		t.Fatal(err)
+	}
+	expected = "5:06AM ERR db: connection lost"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestDefaultLogTemplateInFactory(t *testing.T) {
+	lines := runFileLogFactory(t, DefaultLogTemplate(true), func(factory *FileLogFactory) {
+		factory.CreateLogger("app", nil, nil).Warn("slow")
+	})
+	// output file is not a terminal, so the level is not colored
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], " WRN app: slow") {
+		t.Errorf("expected a single warning line but received %q", lines)
+	}
+}
+
+func TestFileLogFactoryWritePlainTextToFile(t *testing.T) {
+	format := template.Must(template.New("log").Parse(`{{.Content}}`))
+	lines := runFileLogFactory(t, format, func(factory *FileLogFactory) {
+		factory.CreateLogger("app", nil, nil).Info(CFormat(Red, "a %v", CContent(Blue, "b").WithStyle(Bold)))
+	})
+	if len(lines) != 1 || lines[0] != "a b" {
+		t.Errorf("expected plain text in the log file but received %q", lines)
+	}
+}
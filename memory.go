@@ -1,8 +1,16 @@
 package helpers
 
 import (
+	"context"
 	"reflect"
 	"sync"
+	"time"
+)
+
+const (
+	ErrBufferManagerClosed    = StringError("Buffer manager is closed")
+	ErrBufferManagerExhausted = StringError("Buffer manager reached its maximum number of buckets")
+	ErrBufferTooLarge         = StringError("Requested buffer is larger than bucket size")
 )
 
 func assert(condition bool, message string) {
@@ -57,15 +65,48 @@ func CreateMemoryItemCollection(list interface{}) MemoryItemCollection {
 
 }
 
+// typedMemoryItemList a slice backed ``MemoryItemCollection`` that does not need reflection
+type typedMemoryItemList[T MemoryItem] []T
+
+func (this typedMemoryItemList[T]) GetSize() int                 { return len(this) }
+func (this typedMemoryItemList[T]) GetItem(index int) MemoryItem { return this[index] }
+
+// typedMemoryItemListFactory wrap a function that create a slice of ``T`` as a ``MemoryItemListFactory``
+func typedMemoryItemListFactory[T MemoryItem](newSlice func(count int) []T) MemoryItemListFactory {
+	if newSlice == nil {
+		panic("Invalid argument")
+	}
+
+	return func(count int) MemoryItemCollection {
+		items := newSlice(count)
+		if items == nil {
+			return nil
+		}
+		return typedMemoryItemList[T](items)
+	}
+}
+
 type MemoryItemListFactory = func(count int) MemoryItemCollection
 
 type AllocatorStats struct {
 	ReservedItems  int
 	AllocatedItems int
 }
+
+// Sub Return difference of this stats and an older snapshot. ``ReservedItems`` is returned as the number of
+// items that reserved in the interval, while ``AllocatedItems`` is the current value of this stats
+func (this AllocatorStats) Sub(older AllocatorStats) AllocatorStats {
+	result := this
+	result.ReservedItems -= older.ReservedItems
+	return result
+}
+
 type Allocator interface {
 	Allocate() MemoryItem
 	Free(data MemoryItem)
+	// Reserve Pre-allocate ``count`` items and add them to the free list, so later allocations do not need to
+	// call the factory
+	Reserve(count int)
 	GetStats() AllocatorStats
 }
 
@@ -99,10 +140,21 @@ func NewSynchedAllocator(burstSize int, factory MemoryItemListFactory) Allocator
 	}
 }
 
-func allocate_memory_items(factory MemoryItemListFactory, count int) (MemoryItem, int) {
+// NewTypedAllocator Create an ``Allocator`` for items of type ``T`` without using reflection.
+// ``newSlice`` must return ``count`` ready to use items(e.g. pointers to elements of a backing array)
+func NewTypedAllocator[T MemoryItem](burstSize int, newSlice func(count int) []T) Allocator {
+	return NewAllocator(burstSize, typedMemoryItemListFactory(newSlice))
+}
+
+// NewSynchedTypedAllocator Create a thread safe ``Allocator`` for items of type ``T`` without using reflection
+func NewSynchedTypedAllocator[T MemoryItem](burstSize int, newSlice func(count int) []T) Allocator {
+	return NewSynchedAllocator(burstSize, typedMemoryItemListFactory(newSlice))
+}
+
+func allocate_memory_items(factory MemoryItemListFactory, count int) (MemoryItem, MemoryItem, int) {
 	items := factory(count)
 	if items == nil {
-		return nil, 0
+		return nil, nil, 0
 	}
 
 	size := items.GetSize()
@@ -112,20 +164,20 @@ func allocate_memory_items(factory MemoryItemListFactory, count int) (MemoryItem
 	for i := 1; i < size; i++ {
 		item := items.GetItem(i)
 		if item == nil {
-			return nil, 0
+			return nil, nil, 0
 		}
 		prev.SetNext(item)
 		prev = item
 	}
 	prev.SetNext(nil)
 
-	return first, size
+	return first, prev, size
 }
 func (this *memoryAllocator) Allocate() MemoryItem {
 	if this.avail == nil {
 		assert(this.reservedItems == this.allocatedItems, "There is no available item, but available counter is not 0")
 
-		avail, size := allocate_memory_items(this.factory, this.burstSize)
+		avail, _, size := allocate_memory_items(this.factory, this.burstSize)
 		if avail == nil {
 			panic("MemoryItem factory should return an slice or array of MemoryItems")
 		}
@@ -150,6 +202,20 @@ func (this *memoryAllocator) Free(item MemoryItem) {
 	this.avail = item
 	this.allocatedItems -= 1
 }
+func (this *memoryAllocator) Reserve(count int) {
+	if count <= 0 {
+		return
+	}
+
+	first, last, size := allocate_memory_items(this.factory, count)
+	if first == nil {
+		panic("MemoryItem factory should return an slice or array of MemoryItems")
+	}
+
+	last.SetNext(this.avail)
+	this.avail = first
+	this.reservedItems += size
+}
 func (this *memoryAllocator) GetStats() AllocatorStats {
 	return AllocatorStats{
 		ReservedItems:  this.reservedItems,
@@ -172,6 +238,11 @@ func (this *synchedMemoryAllocator) Free(item MemoryItem) {
 	defer this.lock.Unlock()
 	this.memoryAllocator.Free(item)
 }
+func (this *synchedMemoryAllocator) Reserve(count int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.memoryAllocator.Reserve(count)
+}
 func (this *synchedMemoryAllocator) GetStats() AllocatorStats {
 	this.lock.Lock()
 	defer this.lock.Unlock()
@@ -260,6 +331,24 @@ func (this *buffer_t) Merge(other *buffer_t) {
 	this.Size = newSize
 }
 
+// ConcatBuffers Copy content of all buffers into a single contiguous slice, ``nil`` buffers will be skipped
+func ConcatBuffers(bufs ...Buffer) []byte {
+	size := 0
+	for i := 0; i < len(bufs); i++ {
+		if bufs[i] != nil {
+			size += bufs[i].GetSize()
+		}
+	}
+
+	result := make([]byte, 0, size)
+	for i := 0; i < len(bufs); i++ {
+		if bufs[i] != nil {
+			result = append(result, bufs[i].GetData()[:bufs[i].GetSize()]...)
+		}
+	}
+	return result
+}
+
 //endregion
 
 //region bucket_t
@@ -346,11 +435,77 @@ type BufferManagerStats struct {
 	BufferAllocatorStats  AllocatorStats
 	BucketAllocatorStats  AllocatorStats
 }
+
+// Sub Return difference of this stats and an older snapshot. Cumulative counters(``TotalAllocatedBuffers`` and
+// ``TotalAllocatedBytes``) are returned as deltas, while current values(like ``ReservedBuckets`` and ``ReservedBytes``)
+// are the values of this stats. Allocator stats are subtracted using ``AllocatorStats.Sub``
+func (this BufferManagerStats) Sub(older BufferManagerStats) BufferManagerStats {
+	result := this
+	result.TotalAllocatedBuffers -= older.TotalAllocatedBuffers
+	result.TotalAllocatedBytes -= older.TotalAllocatedBytes
+	result.BufferAllocatorStats = this.BufferAllocatorStats.Sub(older.BufferAllocatorStats)
+	result.BucketAllocatorStats = this.BucketAllocatorStats.Sub(older.BucketAllocatorStats)
+	return result
+}
+
 type BufferManager interface {
 	GetBucketSize() int
+	// SetBucketSize Change size of the buckets that will be created after this call, existing buckets keep
+	// their size, so buckets with different sizes may coexist in the manager
+	SetBucketSize(size int)
 	Allocate(size int) Buffer
+	// AllocateCtx Allocate a buffer, if the manager is bounded and there is no free space, synched managers wait
+	// until a buffer is freed, `ctx` is done or the manager is closed
+	AllocateCtx(ctx context.Context, size int) (Buffer, error)
 	Free(buffer Buffer)
+	// Trim Shrink ``buffer`` to ``usedSize`` and release its tail, so it can be used by other allocations.
+	// ``buffer`` must not be used after this call and the returned buffer must be used instead of it
+	Trim(buffer Buffer, usedSize int) Buffer
+	// Warmup Create buckets until at least ``bytes`` bytes are reserved, so allocations do not need to create
+	// buckets at startup. Bounded managers never exceed their maximum number of buckets or their budget
+	Warmup(bytes int)
 	GetStats() BufferManagerStats
+	// Close Release all callers that are waiting in `AllocateCtx` with `ErrBufferManagerClosed` and
+	// prevent new allocations, allocated buffers may still be freed
+	Close() error
+}
+
+// MemoryBudget a limit on total bytes that are reserved by buckets of all buffer managers that share it.
+// Buckets are never released, so reserved bytes of the budget only grow
+type MemoryBudget struct {
+	lock     sync.Mutex
+	limit    int
+	reserved int
+}
+
+// NewMemoryBudget Create a ``MemoryBudget`` that allow at most ``limit`` bytes to be reserved
+func NewMemoryBudget(limit int) *MemoryBudget {
+	if limit <= 0 {
+		panic("Invalid argument")
+	}
+	return &MemoryBudget{limit: limit}
+}
+
+func (this *MemoryBudget) Limit() int { return this.limit }
+func (this *MemoryBudget) Reserved() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.reserved
+}
+func (this *MemoryBudget) Available() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.limit - this.reserved
+}
+func (this *MemoryBudget) try_reserve(size int) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.reserved+size > this.limit {
+		return false
+	}
+	this.reserved += size
+	return true
 }
 
 var sentry_bucket = &bucket_t{}
@@ -360,6 +515,9 @@ type bufferManager struct {
 	BucketAllocator Allocator
 	Buckets         *bucket_t
 	BucketSize      int
+	MaxBuckets      int
+	Budget          *MemoryBudget
+	Closed          bool
 
 	ReservedBuckets       int
 	ReservedBytes         int
@@ -372,6 +530,8 @@ type bufferManager struct {
 type syncBufferManager struct {
 	bufferManager
 	Lock sync.Mutex
+	// Released closed when a buffer is freed or the manager is closed, to wake up waiting allocators
+	Released chan struct{}
 }
 
 func NewBufferManager(bucketSize, bucketAllocatorBurst, bufferAllocatorBurst int) BufferManager {
@@ -385,6 +545,32 @@ func NewSynchedBufferManager(bucketSize, bucketAllocatorBurst, bufferAllocatorBu
 	return result
 }
 
+// NewBoundedBufferManager Create a synched `BufferManager` that never reserve more than `maxBuckets` buckets,
+// when all buckets are full `Allocate` return nil and `AllocateCtx` wait for a buffer to be freed
+func NewBoundedBufferManager(bucketSize, maxBuckets, bucketAllocatorBurst, bufferAllocatorBurst int) BufferManager {
+	if maxBuckets <= 0 {
+		panic("Invalid argument")
+	}
+
+	result := &syncBufferManager{Lock: sync.Mutex{}}
+	result.bufferManager.initialize(bucketSize, bucketAllocatorBurst, bufferAllocatorBurst)
+	result.MaxBuckets = maxBuckets
+	return result
+}
+
+// NewBufferManagerWithBudget Create a synched ``BufferManager`` that reserve its buckets from ``budget``, when
+// the budget is exhausted ``Allocate`` return nil and ``AllocateCtx`` wait for a buffer of this manager to be freed
+func NewBufferManagerWithBudget(budget *MemoryBudget, bucketSize, bucketAllocatorBurst, bufferAllocatorBurst int) BufferManager {
+	if budget == nil {
+		panic("Invalid argument")
+	}
+
+	result := &syncBufferManager{Lock: sync.Mutex{}}
+	result.bufferManager.initialize(bucketSize, bucketAllocatorBurst, bufferAllocatorBurst)
+	result.Budget = budget
+	return result
+}
+
 func (this *bufferManager) initialize(bucketSize, bucketAllocatorBurst, bufferAllocatorBurst int) {
 	this.BucketSize = bucketSize
 	this.BucketAllocator = NewAllocator(bucketAllocatorBurst, func(count int) MemoryItemCollection {
@@ -406,6 +592,12 @@ func (this *bufferManager) createBucket() *bucket_t {
 	this.ReservedBytes += this.BucketSize
 	return newBucket
 }
+func (this *bufferManager) can_create_bucket() bool {
+	if this.MaxBuckets > 0 && this.ReservedBuckets >= this.MaxBuckets {
+		return false
+	}
+	return this.Budget == nil || this.Budget.try_reserve(this.BucketSize)
+}
 func (this *bufferManager) try_remove_bucket(pbucket **bucket_t) {
 	bucket := *pbucket
 	if bucket.FreeBuffers == nil {
@@ -438,6 +630,9 @@ func (this *bufferManager) do_allocate(size int) *buffer_t {
 	}
 
 	// there was no buffer that have enough space to allocate the buffer
+	if !this.can_create_bucket() {
+		return nil
+	}
 	newBucket := this.createBucket()
 	buffer = newBucket.Allocate(size, this.BufferAllocator)
 	this.try_insert_bucket(newBucket)
@@ -445,12 +640,21 @@ func (this *bufferManager) do_allocate(size int) *buffer_t {
 }
 
 func (this *bufferManager) GetBucketSize() int { return this.BucketSize }
+func (this *bufferManager) SetBucketSize(size int) {
+	if size <= 0 {
+		panic("Invalid argument")
+	}
+	this.BucketSize = size
+}
 func (this *bufferManager) Allocate(size int) Buffer {
-	if size > this.BucketSize {
+	if size > this.BucketSize || this.Closed {
 		return nil
 	}
 
 	buffer := this.do_allocate(size)
+	if buffer == nil {
+		return nil
+	}
 	this.AllocatedBuffers += 1
 	this.TotalAllocatedBuffers += 1
 	this.AllocatedBytes += size
@@ -458,6 +662,26 @@ func (this *bufferManager) Allocate(size int) Buffer {
 	buffer.Next = nil
 	return buffer
 }
+func (this *bufferManager) allocateOrError(size int) (Buffer, error) {
+	if this.Closed {
+		return nil, ErrBufferManagerClosed
+	}
+	if size > this.BucketSize {
+		return nil, ErrBufferTooLarge
+	}
+	if buffer := this.Allocate(size); buffer != nil {
+		return buffer, nil
+	}
+	return nil, ErrBufferManagerExhausted
+}
+func (this *bufferManager) AllocateCtx(ctx context.Context, size int) (Buffer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// nothing can free a buffer while we wait in a manager that is not synched, so there is no point in waiting
+	return this.allocateOrError(size)
+}
 func (this *bufferManager) Free(buffer Buffer) {
 	if buffer == nil {
 		return
@@ -470,11 +694,52 @@ func (this *bufferManager) Free(buffer Buffer) {
 
 	this.AllocatedBuffers -= 1
 	this.AllocatedBytes -= buf.Size
-	buf.Bucket.Release(buf, this.BufferAllocator)
-	if buf.Bucket.Next == sentry_bucket {
+	this.release_buffer(buf)
+}
+func (this *bufferManager) release_buffer(buf *buffer_t) {
+	bucket := buf.Bucket
+	bucket.Release(buf, this.BufferAllocator)
+	if bucket.Next == sentry_bucket {
 		this.AvailableBuckets += 1
-		buf.Bucket.Next = this.Buckets
-		this.Buckets = buf.Bucket
+		bucket.Next = this.Buckets
+		this.Buckets = bucket
+	}
+}
+func (this *bufferManager) Trim(buffer Buffer, usedSize int) Buffer {
+	if buffer == nil {
+		return nil
+	}
+
+	buf, ok := buffer.(*buffer_t)
+	if !ok {
+		panic("Invalid buffer")
+	}
+	if usedSize < 0 || usedSize > buf.Size {
+		panic("Invalid argument")
+	}
+
+	if usedSize == buf.Size {
+		return buf
+	}
+	if usedSize == 0 {
+		this.Free(buf)
+		return nil
+	}
+
+	// after the cut ``buf`` is the tail of the buffer
+	head := buf.Cut(usedSize, this.BufferAllocator)
+	head.Data = head.Data[:usedSize:usedSize]
+	head.Next = nil
+	this.AllocatedBytes -= buf.Size
+	this.release_buffer(buf)
+	return head
+}
+func (this *bufferManager) Warmup(bytes int) {
+	for this.ReservedBytes < bytes {
+		if !this.can_create_bucket() {
+			return
+		}
+		this.try_insert_bucket(this.createBucket())
 	}
 }
 func (this *bufferManager) GetStats() BufferManagerStats {
@@ -490,19 +755,80 @@ func (this *bufferManager) GetStats() BufferManagerStats {
 		BucketAllocatorStats:  this.BucketAllocator.GetStats(),
 	}
 }
+func (this *bufferManager) Close() error {
+	this.Closed = true
+	return nil
+}
 
-func (this *syncBufferManager) GetBucketSize() int { return this.bufferManager.BucketSize }
+func (this *syncBufferManager) GetBucketSize() int {
+	this.Lock.Lock()
+	defer this.Lock.Unlock()
+
+	return this.bufferManager.BucketSize
+}
+func (this *syncBufferManager) SetBucketSize(size int) {
+	this.Lock.Lock()
+	defer this.Lock.Unlock()
+
+	this.bufferManager.SetBucketSize(size)
+}
 func (this *syncBufferManager) Allocate(size int) Buffer {
 	this.Lock.Lock()
 	defer this.Lock.Unlock()
 
 	return this.bufferManager.Allocate(size)
 }
+func (this *syncBufferManager) AllocateCtx(ctx context.Context, size int) (Buffer, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		this.Lock.Lock()
+		buffer, err := this.bufferManager.allocateOrError(size)
+		if err != ErrBufferManagerExhausted {
+			this.Lock.Unlock()
+			return buffer, err
+		}
+		if this.Released == nil {
+			this.Released = make(chan struct{})
+		}
+		released := this.Released
+		this.Lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+func (this *syncBufferManager) wakeWaiters() {
+	if this.Released != nil {
+		close(this.Released)
+		this.Released = nil
+	}
+}
 func (this *syncBufferManager) Free(buffer Buffer) {
 	this.Lock.Lock()
 	defer this.Lock.Unlock()
 
 	this.bufferManager.Free(buffer)
+	this.wakeWaiters()
+}
+func (this *syncBufferManager) Trim(buffer Buffer, usedSize int) Buffer {
+	this.Lock.Lock()
+	defer this.Lock.Unlock()
+
+	result := this.bufferManager.Trim(buffer, usedSize)
+	this.wakeWaiters()
+	return result
+}
+func (this *syncBufferManager) Warmup(bytes int) {
+	this.Lock.Lock()
+	defer this.Lock.Unlock()
+
+	this.bufferManager.Warmup(bytes)
 }
 func (this *syncBufferManager) GetStats() BufferManagerStats {
 	this.Lock.Lock()
@@ -510,3 +836,43 @@ func (this *syncBufferManager) GetStats() BufferManagerStats {
 
 	return this.bufferManager.GetStats()
 }
+func (this *syncBufferManager) Close() error {
+	this.Lock.Lock()
+	defer this.Lock.Unlock()
+
+	this.bufferManager.Close()
+	this.wakeWaiters()
+	return nil
+}
+
+// SubscribeBufferManagerStats Send a snapshot of stats of ``manager`` to the returned channel every ``interval``
+// until the returned cancel function is called. Snapshots are dropped while the receiver is not ready and the
+// channel is closed after cancel
+func SubscribeBufferManagerStats(manager BufferManager, interval time.Duration) (<-chan BufferManagerStats, func()) {
+	if manager == nil || interval <= 0 {
+		panic("Invalid argument")
+	}
+
+	result := make(chan BufferManagerStats, 1)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer close(result)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				select {
+				case result <- manager.GetStats():
+				default:
+				}
+			}
+		}
+	}()
+
+	cancel := sync.Once{}
+	return result, func() { cancel.Do(func() { close(done) }) }
+}
@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"sync"
+)
+
+// MemoryLogFactory a `LogFactory` that keep all records in the memory, it is mostly useful in tests
+type MemoryLogFactory struct {
+	lock           sync.Mutex
+	records        []LogRecord
+	minimumLevel   LogLevel
+	verbosityLevel int
+	colorMap       *ColorNameMap
+}
+
+// NewMemoryLogFactory Create a `MemoryLogFactory`
+func NewMemoryLogFactory(minimumLogLevel LogLevel, verbosityLevel int) *MemoryLogFactory {
+	return &MemoryLogFactory{
+		minimumLevel:   minimumLogLevel,
+		verbosityLevel: verbosityLevel,
+		colorMap:       GetGlobalColorMap(),
+	}
+}
+
+func (this *MemoryLogFactory) getColorMap() *ColorNameMap { return this.colorMap }
+func (this *MemoryLogFactory) resolveLevel(source string, inherited LogLevel) LogLevel {
+	return inherited
+}
+func (this *MemoryLogFactory) dispatchRecord(rec *LogRecord) {
+	rec.context = MonoColor
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.records = append(this.records, *rec)
+}
+
+func (this *MemoryLogFactory) Close() error { return nil }
+func (this *MemoryLogFactory) CreateLogger(name string, minimumLogLevel *LogLevel, verbosityLevel *int) Logger {
+	if minimumLogLevel == nil {
+		minimumLogLevel = &this.minimumLevel
+	}
+	if verbosityLevel == nil {
+		verbosityLevel = &this.verbosityLevel
+	}
+	return FileLogger{
+		factory:        this,
+		name:           name,
+		minimumLevel:   *minimumLogLevel,
+		verbosityLevel: *verbosityLevel,
+	}
+}
+
+// Records Return a copy of all records that logged by loggers of this factory
+func (this *MemoryLogFactory) Records() []LogRecord {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	result := make([]LogRecord, len(this.records))
+	copy(result, this.records)
+	return result
+}
+
+// RecordsAt Return all records that logged with specified level
+func (this *MemoryLogFactory) RecordsAt(level LogLevel) []LogRecord {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	result := []LogRecord{}
+	for i := 0; i < len(this.records); i++ {
+		if this.records[i].Level == level {
+			result = append(result, this.records[i])
+		}
+	}
+	return result
+}
+
+// Reset Remove all records of this factory
+func (this *MemoryLogFactory) Reset() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.records = nil
+}
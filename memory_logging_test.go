@@ -0,0 +1,130 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryLogFactoryRecords(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	logger := factory.CreateLogger("test", nil, nil)
+
+	logger.Info("first")
+	logger.Errorf("second %d", 2)
+	logger.Info("third")
+
+	records := factory.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records but received %d", len(records))
+	}
+	if records[1].LogSource != "test" || records[1].Message() != "second 2" {
+		t.Errorf("unexpected record: %q %q", records[1].LogSource, records[1].Message())
+	}
+
+	errors := factory.RecordsAt(Error)
+	if len(errors) != 1 || errors[0].Message() != "second 2" {
+		t.Errorf("expected a single error record but received %v", errors)
+	}
+	if infos := factory.RecordsAt(Info); len(infos) != 2 {
+		t.Errorf("expected 2 info records but received %d", len(infos))
+	}
+
+	factory.Reset()
+	if records := factory.Records(); len(records) != 0 {
+		t.Errorf("expected no record after reset but received %d", len(records))
+	}
+}
+
+func TestMemoryLogFactoryServiceLifecycle(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 10)
+	executer := CreateServiceExecuter(factory)
+
+	stopRequested := make(chan struct{})
+	close(stopRequested)
+	if err := executer.RunService(newBlockingService("svc"), stopRequested); err != nil {
+		t.Fatalf("expected nil error but received %v", err)
+	}
+
+	messages := map[string]bool{}
+	records := factory.Records()
+	for i := 0; i < len(records); i++ {
+		if records[i].LogSource != "services/svc" {
+			t.Errorf("expected %q but received %q", "services/svc", records[i].LogSource)
+		}
+		messages[records[i].Message()] = true
+	}
+	expected := []string{
+		"Running service in the background",
+		"Received stop signal, shutting down the service",
+		"Service stopped: <nil>",
+	}
+	for i := 0; i < len(expected); i++ {
+		if !messages[expected[i]] {
+			t.Errorf("expected %q to be logged", expected[i])
+		}
+	}
+}
+
+type testInstanceIDKey struct{}
+
+func TestServiceExecuterDecorator(t *testing.T) {
+	RegisterLogContextExtractor("test.instance", LogContextValueExtractor(testInstanceIDKey{}))
+	defer func() {
+		logContextExtractorsLock.Lock()
+		delete(logContextExtractors, "test.instance")
+		logContextExtractorsLock.Unlock()
+	}()
+
+	factory := NewMemoryLogFactory(Debug, 10)
+	ctx := context.WithValue(context.Background(), testInstanceIDKey{}, "instance-1")
+	executer := CreateServiceExecuterWithDecorator(factory, func(logger Logger) Logger {
+		return logger.WithContext(ctx)
+	})
+
+	stopRequested := make(chan struct{})
+	close(stopRequested)
+	if err := executer.RunService(newBlockingService("svc"), stopRequested); err != nil {
+		t.Fatalf("expected nil error but received %v", err)
+	}
+	stopped := executer.ExecuteAsyncService(newBlockingAsyncService("async"), stopRequested)
+	if err := <-stopped; err != nil {
+		t.Fatalf("expected nil error but received %v", err)
+	}
+
+	records := factory.Records()
+	if len(records) == 0 {
+		t.Fatal("expected lifecycle records to be logged")
+	}
+	for i := 0; i < len(records); i++ {
+		if value := records[i].Fields["test.instance"]; value != "instance-1" {
+			t.Errorf("expected %q in %q but received %v", "instance-1", records[i].Message(), value)
+		}
+	}
+}
+
+func TestServiceLoggerMatchExecuterLogger(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 10)
+	service := newBlockingService("svc")
+
+	logger := ServiceLogger(factory, service)
+	if logger.GetName() != "services/svc" {
+		t.Errorf("expected %q but received %q", "services/svc", logger.GetName())
+	}
+	logger.Info("from service")
+
+	stopRequested := make(chan struct{})
+	close(stopRequested)
+	if err := CreateServiceExecuter(factory).RunService(service, stopRequested); err != nil {
+		t.Fatal(err)
+	}
+
+	records := factory.Records()
+	if len(records) < 2 {
+		t.Fatalf("expected records of the service and the executer but received %d", len(records))
+	}
+	for i := 0; i < len(records); i++ {
+		if records[i].LogSource != logger.GetName() {
+			t.Errorf("expected %q but received %q", logger.GetName(), records[i].LogSource)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package helpers
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// testItem a user defined `MemoryItem`
+type testItem struct {
+	next  MemoryItem
+	Value int
+}
+
+func (this *testItem) GetNext() MemoryItem      { return this.next }
+func (this *testItem) SetNext(value MemoryItem) { this.next = value }
+func (this *testItem) Reset()                   { this.Value = 0 }
+
+func newTestItems(count int) []*testItem {
+	backing := make([]testItem, count)
+	result := make([]*testItem, count)
+	for i := 0; i < count; i++ {
+		result[i] = &backing[i]
+	}
+	return result
+}
+
+func TestTypedAllocator(t *testing.T) {
+	factoryCalls := 0
+	allocator := NewTypedAllocator(4, func(count int) []*testItem {
+		factoryCalls++
+		return newTestItems(count)
+	})
+
+	items := make([]*testItem, 6)
+	for i := 0; i < len(items); i++ {
+		item, ok := allocator.Allocate().(*testItem)
+		if !ok {
+			t.Fatalf("expected a *testItem")
+		}
+		item.Value = i + 1
+		items[i] = item
+	}
+	if factoryCalls != 2 {
+		t.Errorf("expected 2 bursts but factory called %d times", factoryCalls)
+	}
+	if stats := allocator.GetStats(); stats != (AllocatorStats{ReservedItems: 8, AllocatedItems: 6}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	allocator.Free(items[5])
+	allocator.Free(items[4])
+	if stats := allocator.GetStats(); stats.AllocatedItems != 4 || stats.ReservedItems != 8 {
+		t.Errorf("unexpected stats after free: %+v", stats)
+	}
+
+	// freed items are reused and reset
+	item := allocator.Allocate().(*testItem)
+	if item != items[4] {
+		t.Errorf("expected the last freed item to be reused")
+	}
+	if item.Value != 0 {
+		t.Errorf("expected a reset item but Value is %d", item.Value)
+	}
+}
+
+func TestSynchedTypedAllocator(t *testing.T) {
+	allocator := NewSynchedTypedAllocator(2, newTestItems)
+	done := make(chan *testItem, 8)
+	for i := 0; i < 8; i++ {
+		go func() { done <- allocator.Allocate().(*testItem) }()
+	}
+	seen := map[*testItem]bool{}
+	for i := 0; i < 8; i++ {
+		item := <-done
+		if seen[item] {
+			t.Fatalf("an item allocated twice")
+		}
+		seen[item] = true
+	}
+	if stats := allocator.GetStats(); stats.AllocatedItems != 8 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestConcatBuffers(t *testing.T) {
+	manager := NewBufferManager(64, 1, 4)
+	parts := []string{"hello", ", ", "", "pooled", " world"}
+	bufs := []Buffer{nil}
+	naive := []byte{}
+	for i := 0; i < len(parts); i++ {
+		buf := manager.Allocate(len(parts[i]))
+		copy(buf.GetData(), parts[i])
+		bufs = append(bufs, buf, nil)
+		naive = append(naive, parts[i]...)
+	}
+
+	result := ConcatBuffers(bufs...)
+	if string(result) != string(naive) {
+		t.Errorf("expected %q but received %q", string(naive), string(result))
+	}
+	if len(ConcatBuffers()) != 0 || len(ConcatBuffers(nil, nil)) != 0 {
+		t.Errorf("expected an empty result for no buffers")
+	}
+
+	// result must not share memory with the pooled buffers
+	result[0] = 'H'
+	if bufs[1].GetData()[0] != 'h' {
+		t.Errorf("expected result to be a copy of the buffers")
+	}
+}
+
+func TestBufferManagerSetBucketSize(t *testing.T) {
+	managers := []BufferManager{NewBufferManager(64, 1, 4), NewSynchedBufferManager(64, 1, 4)}
+	for i := 0; i < len(managers); i++ {
+		manager := managers[i]
+		small := manager.Allocate(64)
+		if stats := manager.GetStats(); stats.ReservedBuckets != 1 || stats.ReservedBytes != 64 {
+			t.Errorf("expected a 64 bytes bucket but received %+v", stats)
+		}
+
+		manager.SetBucketSize(256)
+		if manager.GetBucketSize() != 256 {
+			t.Errorf("expected bucket size 256 but received %d", manager.GetBucketSize())
+		}
+		large := manager.Allocate(200)
+		if large == nil || large.GetSize() != 200 {
+			t.Fatalf("expected a 200 bytes buffer from the new bucket")
+		}
+		stats := manager.GetStats()
+		if stats.ReservedBuckets != 2 || stats.ReservedBytes != 64+256 {
+			t.Errorf("expected buckets of 64 and 256 bytes but received %+v", stats)
+		}
+
+		// existing bucket is still usable after it is freed
+		manager.Free(small)
+		manager.Free(large)
+		if stats = manager.GetStats(); stats.AllocatedBuffers != 0 || stats.ReservedBytes != 64+256 {
+			t.Errorf("expected existing buckets to be kept but received %+v", stats)
+		}
+	}
+}
+
+func TestBoundedBufferManagerWaitForFree(t *testing.T) {
+	manager := NewBoundedBufferManager(64, 1, 1, 4)
+	full := manager.Allocate(64)
+	if full == nil {
+		t.Fatal("expected first allocation to succeed")
+	}
+	if manager.Allocate(1) != nil {
+		t.Errorf("expected Allocate to fail when all buckets are full")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := manager.AllocateCtx(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected %v but received %v", context.DeadlineExceeded, err)
+	}
+
+	result := make(chan Buffer, 1)
+	go func() {
+		buffer, _ := manager.AllocateCtx(context.Background(), 32)
+		result <- buffer
+	}()
+	time.Sleep(10 * time.Millisecond)
+	manager.Free(full)
+	select {
+	case buffer := <-result:
+		if buffer == nil || buffer.GetSize() != 32 {
+			t.Errorf("expected a 32 bytes buffer after free")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting allocation was not released by free")
+	}
+}
+
+func TestBoundedBufferManagerClose(t *testing.T) {
+	manager := NewBoundedBufferManager(64, 1, 1, 4)
+	full := manager.Allocate(64)
+
+	const waiters = 5
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			_, err := manager.AllocateCtx(context.Background(), 1)
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := manager.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < waiters; i++ {
+		select {
+		case err := <-errs:
+			if err != ErrBufferManagerClosed {
+				t.Errorf("expected %v but received %v", ErrBufferManagerClosed, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("waiting allocation was not released by close")
+		}
+	}
+
+	if _, err := manager.AllocateCtx(context.Background(), 1); err != ErrBufferManagerClosed {
+		t.Errorf("expected %v but received %v", ErrBufferManagerClosed, err)
+	}
+	manager.Free(full)
+	if manager.Allocate(1) != nil {
+		t.Errorf("expected allocation to fail after close even if there is free space")
+	}
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 {
+		t.Errorf("expected buffers to be freed after close but received %+v", stats)
+	}
+}
+
+func TestBufferManagerStatsSub(t *testing.T) {
+	manager := NewBufferManager(64, 1, 4)
+	first := manager.Allocate(16)
+	older := manager.GetStats()
+
+	second := manager.Allocate(32)
+	third := manager.Allocate(64)
+	manager.Free(first)
+	newer := manager.GetStats()
+
+	diff := newer.Sub(older)
+	if diff.TotalAllocatedBuffers != 2 || diff.TotalAllocatedBytes != 32+64 {
+		t.Errorf("expected 2 buffers and %d bytes to be allocated in the interval but received %+v", 32+64, diff)
+	}
+	if diff.ReservedBuckets != newer.ReservedBuckets || diff.ReservedBytes != newer.ReservedBytes ||
+		diff.AvailableBuckets != newer.AvailableBuckets || diff.AllocatedBuffers != newer.AllocatedBuffers ||
+		diff.AllocatedBytes != newer.AllocatedBytes {
+		t.Errorf("expected current values of %+v but received %+v", newer, diff)
+	}
+	if diff.BufferAllocatorStats != newer.BufferAllocatorStats.Sub(older.BufferAllocatorStats) ||
+		diff.BucketAllocatorStats != newer.BucketAllocatorStats.Sub(older.BucketAllocatorStats) {
+		t.Errorf("expected allocator stats of %+v to be subtracted but received %+v", newer, diff)
+	}
+
+	manager.Free(second)
+	manager.Free(third)
+}
+
+func TestAllocatorStatsSub(t *testing.T) {
+	older := AllocatorStats{ReservedItems: 4, AllocatedItems: 3}
+	newer := AllocatorStats{ReservedItems: 8, AllocatedItems: 1}
+	expected := AllocatorStats{ReservedItems: 4, AllocatedItems: 1}
+	if diff := newer.Sub(older); diff != expected {
+		t.Errorf("expected %+v but received %+v", expected, diff)
+	}
+
+	// items that reserved by a real allocator are counted in the interval
+	allocator := NewTypedAllocator(1, newTestItems)
+	allocator.Reserve(2)
+	before := allocator.GetStats()
+	item := allocator.Allocate()
+	allocator.Reserve(3)
+	diff := allocator.GetStats().Sub(before)
+	if diff.ReservedItems != 3 || diff.AllocatedItems != 1 {
+		t.Errorf("expected 3 reserved and 1 allocated item but received %+v", diff)
+	}
+	allocator.Free(item)
+}
+
+func TestBufferManagerTrim(t *testing.T) {
+	managers := []BufferManager{NewBufferManager(64, 1, 4), NewSynchedBufferManager(64, 1, 4)}
+	for i := 0; i < len(managers); i++ {
+		manager := managers[i]
+		buf := manager.Allocate(64)
+		copy(buf.GetData(), "hello")
+
+		trimmed := manager.Trim(buf, 16)
+		if trimmed.GetSize() != 16 || len(trimmed.GetData()) != 16 || cap(trimmed.GetData()) != 16 {
+			t.Errorf("expected a 16 bytes buffer but received %d(%d)", trimmed.GetSize(), cap(trimmed.GetData()))
+		}
+		if string(trimmed.GetData()[:5]) != "hello" {
+			t.Errorf("expected content to be kept but received %q", trimmed.GetData()[:5])
+		}
+		if stats := manager.GetStats(); stats.AllocatedBytes != 16 || stats.AllocatedBuffers != 1 {
+			t.Errorf("expected 16 allocated bytes in 1 buffer but received %+v", stats)
+		}
+
+		// the freed tail must be used by the next allocation instead of a new bucket
+		tail := manager.Allocate(48)
+		if tail == nil {
+			t.Fatalf("expected the tail to be available")
+		}
+		if stats := manager.GetStats(); stats.ReservedBuckets != 1 || stats.AllocatedBytes != 64 {
+			t.Errorf("expected the tail to be reused but received %+v", stats)
+		}
+
+		if same := manager.Trim(tail, 48); same != tail {
+			t.Errorf("expected trim to the same size to return the buffer itself")
+		}
+		if none := manager.Trim(tail, 0); none != nil {
+			t.Errorf("expected trim to zero to free the buffer")
+		}
+		manager.Free(trimmed)
+		if stats := manager.GetStats(); stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 {
+			t.Errorf("expected all buffers to be freed but received %+v", stats)
+		}
+		if whole := manager.Allocate(64); whole == nil || manager.GetStats().ReservedBuckets != 1 {
+			t.Errorf("expected freed parts to be merged back into the bucket")
+		}
+	}
+}
+
+func TestSubscribeBufferManagerStats(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	manager := NewSynchedBufferManager(64, 1, 4)
+	buf := manager.Allocate(16)
+
+	interval := 20 * time.Millisecond
+	stats, cancel := SubscribeBufferManagerStats(manager, interval)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		select {
+		case snapshot := <-stats:
+			if snapshot.AllocatedBuffers != 1 || snapshot.AllocatedBytes != 16 {
+				t.Errorf("expected a snapshot of the manager but received %+v", snapshot)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a snapshot")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 3*interval-interval/2 {
+		t.Errorf("expected 3 snapshots to take about %v but they took %v", 3*interval, elapsed)
+	}
+
+	cancel()
+	cancel() // cancel must be idempotent
+	for range stats {
+		// drain the snapshot that may be sent before cancel
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("expected %d goroutines after cancel but there are %d", goroutines, n)
+	}
+	manager.Free(buf)
+}
+
+func TestAllocatorReserve(t *testing.T) {
+	factoryCalls := 0
+	allocator := NewTypedAllocator(4, func(count int) []*testItem {
+		factoryCalls++
+		return newTestItems(count)
+	})
+
+	allocator.Reserve(10)
+	allocator.Reserve(0)
+	if stats := allocator.GetStats(); stats != (AllocatorStats{ReservedItems: 10, AllocatedItems: 0}) {
+		t.Errorf("expected 10 reserved items and no allocated item but received %+v", stats)
+	}
+
+	// reserved items are used without calling the factory again
+	for i := 0; i < 10; i++ {
+		allocator.Allocate()
+	}
+	if factoryCalls != 1 {
+		t.Errorf("expected a single factory call but factory called %d times", factoryCalls)
+	}
+	if stats := allocator.GetStats(); stats != (AllocatorStats{ReservedItems: 10, AllocatedItems: 10}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestBufferManagerWarmup(t *testing.T) {
+	managers := []BufferManager{NewBufferManager(64, 1, 4), NewSynchedBufferManager(64, 1, 4)}
+	for i := 0; i < len(managers); i++ {
+		manager := managers[i]
+		manager.Warmup(150)
+		stats := manager.GetStats()
+		if stats.ReservedBuckets != 3 || stats.ReservedBytes != 192 || stats.AvailableBuckets != 3 {
+			t.Errorf("expected 3 reserved buckets but received %+v", stats)
+		}
+		if stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 || stats.TotalAllocatedBuffers != 0 {
+			t.Errorf("expected no allocated buffer but received %+v", stats)
+		}
+
+		// warmup never shrink the manager
+		manager.Warmup(10)
+		if stats = manager.GetStats(); stats.ReservedBuckets != 3 {
+			t.Errorf("expected 3 reserved buckets but received %+v", stats)
+		}
+
+		bufs := []Buffer{manager.Allocate(64), manager.Allocate(64), manager.Allocate(64)}
+		if stats = manager.GetStats(); stats.ReservedBuckets != 3 {
+			t.Errorf("expected allocations to use warmed up buckets but received %+v", stats)
+		}
+		for j := 0; j < len(bufs); j++ {
+			manager.Free(bufs[j])
+		}
+	}
+}
+
+func TestBoundedBufferManagerWarmup(t *testing.T) {
+	manager := NewBoundedBufferManager(64, 2, 1, 4)
+	manager.Warmup(1000)
+	if stats := manager.GetStats(); stats.ReservedBuckets != 2 || stats.ReservedBytes != 128 {
+		t.Errorf("expected warmup to stop at the maximum number of buckets but received %+v", stats)
+	}
+}
+
+func TestMemoryBudgetSharedByManagers(t *testing.T) {
+	budget := NewMemoryBudget(128)
+	first := NewBufferManagerWithBudget(budget, 64, 1, 4)
+	second := NewBufferManagerWithBudget(budget, 64, 1, 4)
+
+	a := first.Allocate(64)
+	b := first.Allocate(64)
+	if a == nil || b == nil {
+		t.Fatal("expected allocations within the budget to succeed")
+	}
+	if budget.Reserved() != 128 || budget.Available() != 0 || budget.Limit() != 128 {
+		t.Errorf("expected the budget to be exhausted but %d of %d is reserved", budget.Reserved(), budget.Limit())
+	}
+
+	if second.Allocate(1) != nil {
+		t.Errorf("expected Allocate to fail when the budget is exhausted")
+	}
+	if stats := second.GetStats(); stats.ReservedBuckets != 0 {
+		t.Errorf("expected no bucket to be created but received %+v", stats)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := second.AllocateCtx(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected %v but received %v", context.DeadlineExceeded, err)
+	}
+
+	// freed buffers are reused by their own manager, but buckets are not returned to the budget
+	first.Free(a)
+	if buf := first.Allocate(32); buf == nil {
+		t.Errorf("expected freed memory to be reused by its manager")
+	}
+	if second.Allocate(1) != nil || budget.Reserved() != 128 {
+		t.Errorf("expected reserved bytes of the budget to be kept after free")
+	}
+}
+
+func TestMemoryBudgetWarmup(t *testing.T) {
+	budget := NewMemoryBudget(100)
+	manager := NewBufferManagerWithBudget(budget, 64, 1, 4)
+	manager.Warmup(1000)
+	if stats := manager.GetStats(); stats.ReservedBuckets != 1 || budget.Reserved() != 64 {
+		t.Errorf("expected warmup to stop at the budget but received %+v(%d)", stats, budget.Reserved())
+	}
+}
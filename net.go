@@ -24,3 +24,77 @@ func IsIPv6(value string) bool {
 	}
 	return ip.To16() != nil
 }
+
+const (
+	// MaxCIDRListHostBits maximum number of host bits of a CIDR that `IterateCIDR` accept(a /16 IPv4 block)
+	MaxCIDRListHostBits = 16
+
+	ErrCIDRTooLarge = StringError("CIDR block is too large to be listed, use ForEachHostInCIDR instead")
+)
+
+func nextIP(ip net.IP) net.IP {
+	result := make(net.IP, len(ip))
+	copy(result, ip)
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i]++
+		if result[i] != 0 {
+			break
+		}
+	}
+	return result
+}
+
+// ForEachHostInCIDR call `fn` for each host address of a CIDR block until it return `false`.
+// Network and broadcast addresses of IPv4 blocks larger than /31 are not host addresses and will be skipped
+func ForEachHostInCIDR(cidr string, fn func(ip net.IP) bool) error {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	ip := ipnet.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	ones, bits := ipnet.Mask.Size()
+	skipNetworkAndBroadcast := bits == 32 && bits-ones > 1
+
+	if skipNetworkAndBroadcast {
+		ip = nextIP(ip)
+	}
+	for ipnet.Contains(ip) {
+		next := nextIP(ip)
+		if skipNetworkAndBroadcast && !ipnet.Contains(next) {
+			break
+		}
+		if !fn(ip) {
+			break
+		}
+		if next.IsUnspecified() {
+			// we wrapped around the address space
+			break
+		}
+		ip = next
+	}
+	return nil
+}
+
+// IterateCIDR return all host addresses of a CIDR block, blocks with more than `MaxCIDRListHostBits` host bits
+// will be rejected with `ErrCIDRTooLarge`
+func IterateCIDR(cidr string) ([]net.IP, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones > MaxCIDRListHostBits {
+		return nil, ErrCIDRTooLarge
+	}
+
+	result := []net.IP{}
+	err = ForEachHostInCIDR(cidr, func(ip net.IP) bool {
+		result = append(result, ip)
+		return true
+	})
+	return result, err
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"net"
+	"testing"
+)
+
+func ipStrings(ips []net.IP) []string {
+	result := make([]string, len(ips))
+	for i := 0; i < len(ips); i++ {
+		result[i] = ips[i].String()
+	}
+	return result
+}
+
+func TestIterateCIDR(t *testing.T) {
+	cases := []struct {
+		cidr     string
+		expected []string
+	}{
+		{"192.168.1.0/30", []string{"192.168.1.1", "192.168.1.2"}},
+		// address of the CIDR is not required to be the network address
+		{"192.168.1.5/30", []string{"192.168.1.5", "192.168.1.6"}},
+		{"10.0.0.0/31", []string{"10.0.0.0", "10.0.0.1"}},
+		{"10.0.0.7/32", []string{"10.0.0.7"}},
+		{"2001:db8::/126", []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+	}
+	for i := 0; i < len(cases); i++ {
+		ips, err := IterateCIDR(cases[i].cidr)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", cases[i].cidr, err)
+			continue
+		}
+		result := ipStrings(ips)
+		if len(result) != len(cases[i].expected) {
+			t.Errorf("%s: expected %v but received %v", cases[i].cidr, cases[i].expected, result)
+			continue
+		}
+		for j := 0; j < len(result); j++ {
+			if result[j] != cases[i].expected[j] {
+				t.Errorf("%s: expected %v but received %v", cases[i].cidr, cases[i].expected, result)
+				break
+			}
+		}
+	}
+}
+
+func TestIterateCIDRErrors(t *testing.T) {
+	if _, err := IterateCIDR("10.0.0.0/15"); err != ErrCIDRTooLarge {
+		t.Errorf("expected %v but received %v", ErrCIDRTooLarge, err)
+	}
+	if ips, err := IterateCIDR("10.0.0.0/16"); err != nil || len(ips) != 65534 {
+		t.Errorf("expected 65534 hosts but received %d(%v)", len(ips), err)
+	}
+	if _, err := IterateCIDR("10.0.0.0"); err == nil {
+		t.Errorf("expected an error for an invalid CIDR")
+	}
+}
+
+func TestForEachHostInCIDRStop(t *testing.T) {
+	visited := []string{}
+	err := ForEachHostInCIDR("10.0.0.0/8", func(ip net.IP) bool {
+		visited = append(visited, ip.String())
+		return len(visited) < 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visited) != 3 || visited[0] != "10.0.0.1" || visited[2] != "10.0.0.3" {
+		t.Errorf("expected [10.0.0.1 10.0.0.2 10.0.0.3] but received %v", visited)
+	}
+}
@@ -0,0 +1,284 @@
+package helpers
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Basic16 a `TTY` context that only use the 16 standard terminal colors
+	Basic16 Basic16Context = true
+	// Palette256 a `TTY` context that only use the xterm 256 color palette
+	Palette256 Palette256Context = true
+)
+
+// ColorLevel color capability of a terminal
+type ColorLevel int
+
+const (
+	NoColorLevel ColorLevel = iota
+	Basic16Level
+	Palette256Level
+	TrueColorLevel
+)
+
+// TerminalColorContext a `ColorContext` that write colors to a terminal using a limited set of colors
+type TerminalColorContext interface {
+	ColorContext
+	// ColorLevel Return colors that are used by this context
+	ColorLevel() ColorLevel
+	// TerminalColorName Return terminal name of `color` after mapping it to the colors of this context
+	TerminalColorName(color Color) ColorName
+}
+
+// colorDistance Return squared euclidean distance of two colors
+func colorDistance(a, b RGBCode) int {
+	dr := int(a.Red()) - int(b.Red())
+	dg := int(a.Green()) - int(b.Green())
+	db := int(a.Blue()) - int(b.Blue())
+	return dr*dr + dg*dg + db*db
+}
+
+// nearestPaletteIndex Return index of the color of `palette` that is nearest to `code`
+func nearestPaletteIndex(code RGBCode, palette []RGBCode) int {
+	result := 0
+	bestDistance := -1
+	for i := 0; i < len(palette); i++ {
+		if distance := colorDistance(code, palette[i]); bestDistance == -1 || distance < bestDistance {
+			result = i
+			bestDistance = distance
+		}
+	}
+	return result
+}
+
+// NearestBasic16 Return index(0-15) of the nearest standard terminal color to this color
+func (this RGBColor) NearestBasic16() uint8 {
+	return uint8(nearestPaletteIndex(this.Code(), ansiBasicColors[:]))
+}
+
+// NearestPalette256 Return index of the nearest color of the xterm 256 color palette to this color. Standard
+// colors(0-15) are never returned, since their actual color is defined by the terminal theme
+func (this RGBColor) NearestPalette256() uint8 {
+	return uint8(16 + nearestPaletteIndex(this.Code(), palette256Colors))
+}
+
+// splitColor Return foreground and background codes of a color
+func splitColor(color Color) (fg, bg RGBCode, hasFg, hasBg bool) {
+	switch color.Coverage() {
+	case Foreground:
+		return color.Code(), NoColorCode, true, false
+	case Background:
+		return NoColorCode, color.Code(), false, true
+	case Both:
+		return color.AsForeground().Code(), color.AsBackground().Code(), true, true
+	default:
+		return NoColorCode, NoColorCode, false, false
+	}
+}
+
+// writeTerminalColored write `b` to `out` using a terminal color and style and reset them after it
+func writeTerminalColored(out io.Writer, clr ColorName, style TextStyle, b []byte) error {
+	if clr.IsEmpty() && style == NoStyle {
+		_, err := out.Write(b)
+		return err
+	}
+
+	if err := writeTerminalColorName(out, clr); err != nil {
+		return err
+	}
+	if err := writeTerminalStyle(out, style); err != nil {
+		return err
+	}
+	if _, err := out.Write(b); err != nil {
+		return err
+	}
+	_, err := out.Write(ttyResetColor)
+	return err
+}
+
+//region Basic16Context: A `TTY` context that map colors to the 16 standard terminal colors
+
+type Basic16Context bool
+
+// Basic16ColorName Return SGR parameters of the nearest standard terminal colors to `color`,
+// e.g. `31` for red and `1;31` for bright red
+func Basic16ColorName(color Color) ColorName {
+	result := ColorName{}
+	fg, bg, hasFg, hasBg := splitColor(color)
+	if hasFg {
+		index := int(RGBColor(fg).NearestBasic16())
+		if index < 8 {
+			result.Foreground = strconv.Itoa(30 + index)
+		} else {
+			result.Foreground = "1;" + strconv.Itoa(30+index-8)
+		}
+	}
+	if hasBg {
+		index := int(RGBColor(bg).NearestBasic16())
+		if index < 8 {
+			result.Background = strconv.Itoa(40 + index)
+		} else {
+			result.Background = strconv.Itoa(100 + index - 8)
+		}
+	}
+	return result
+}
+
+func (this Basic16Context) Name() string { return "Basic16" }
+func (this Basic16Context) ColorLevel() ColorLevel {
+	if this {
+		return Basic16Level
+	}
+	return NoColorLevel
+}
+func (this Basic16Context) TerminalColorName(color Color) ColorName {
+	if this {
+		return Basic16ColorName(color)
+	}
+	return ColorName{}
+}
+func (this Basic16Context) Write(w *ColoredWriter, b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	if !this {
+		_, err := w.GetWriter().Write(b)
+		return err
+	}
+	return writeTerminalColored(w.GetWriter(), this.TerminalColorName(w.GetColor()), w.GetStyle(), b)
+}
+
+//endregion
+
+//region Palette256Context: A `TTY` context that map colors to the xterm 256 color palette
+
+type Palette256Context bool
+
+// palette256Colors colors of the xterm 256 color palette except the 16 standard colors, that their actual
+// color is defined by the terminal theme
+var palette256Colors = func() []RGBCode {
+	result := make([]RGBCode, 240)
+	for i := 0; i < len(result); i++ {
+		result[i] = ANSIPaletteColor(uint8(16 + i))
+	}
+	return result
+}()
+
+// Palette256ColorName Return SGR parameters of the nearest colors of the xterm 256 color palette to `color`
+func Palette256ColorName(color Color) ColorName {
+	result := ColorName{}
+	fg, bg, hasFg, hasBg := splitColor(color)
+	if hasFg {
+		result.Foreground = "38;5;" + strconv.Itoa(int(RGBColor(fg).NearestPalette256()))
+	}
+	if hasBg {
+		result.Background = "48;5;" + strconv.Itoa(int(RGBColor(bg).NearestPalette256()))
+	}
+	return result
+}
+
+func (this Palette256Context) Name() string { return "Palette256" }
+func (this Palette256Context) ColorLevel() ColorLevel {
+	if this {
+		return Palette256Level
+	}
+	return NoColorLevel
+}
+func (this Palette256Context) TerminalColorName(color Color) ColorName {
+	if this {
+		return Palette256ColorName(color)
+	}
+	return ColorName{}
+}
+func (this Palette256Context) Write(w *ColoredWriter, b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	if !this {
+		_, err := w.GetWriter().Write(b)
+		return err
+	}
+	return writeTerminalColored(w.GetWriter(), this.TerminalColorName(w.GetColor()), w.GetStyle(), b)
+}
+
+//endregion
+
+// DetectColorLevel Detect color capability of the terminal that `f` is attached to using `COLORTERM` and
+// `TERM` environment variables
+func DetectColorLevel(f *os.File) ColorLevel {
+	return detectColorLevel(IsTerminal(f), os.Getenv)
+}
+func detectColorLevel(isTerminal bool, getenv func(string) string) ColorLevel {
+	if !isTerminal {
+		return NoColorLevel
+	}
+
+	colorTerm := strings.ToLower(getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" || getenv("WT_SESSION") != "" {
+		return TrueColorLevel
+	}
+
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case term == "dumb":
+		return NoColorLevel
+	case strings.Contains(term, "256color"):
+		return Palette256Level
+	default:
+		return Basic16Level
+	}
+}
+
+// TTYContextWithDepth Return a `TTY` context that only use colors of `depth`, `NoColorLevel` return `MonoColor`.
+// `TerminalColorName` of the result map colors to `depth`
+func TTYContextWithDepth(depth ColorLevel) TerminalColorContext {
+	switch depth {
+	case TrueColorLevel:
+		return TTY
+	case Palette256Level:
+		return Palette256
+	case Basic16Level:
+		return Basic16
+	default:
+		return MonoColor
+	}
+}
+
+// GetBestContext Return the context that use the best colors that are supported by the terminal of `w`
+func GetBestContext(w io.Writer) ColorContext {
+	return TTYContextWithDepth(detectColorLevel(IsTerminalWriter(w) || isColorablePipe(w), os.Getenv))
+}
+
+// ColorContextFromEnv Return the context that must be used to write to `w` considering the environment:
+// a non-empty `FORCE_COLOR` force a `TTY` context even for pipes(`1`, `2` and `3` select 16 colors, 256 colors
+// and true color), otherwise a non-empty `NO_COLOR` force `MonoColor` and otherwise the context is detected
+// from the terminal of `w`(see `GetBestContext`)
+func ColorContextFromEnv(w io.Writer) ColorContext {
+	return colorContextFromEnv(IsTerminalWriter(w) || isColorablePipe(w), os.Getenv)
+}
+func colorContextFromEnv(isTerminal bool, getenv func(string) string) ColorContext {
+	switch force := strings.ToLower(getenv("FORCE_COLOR")); force {
+	case "", "0", "false":
+	case "1":
+		return Basic16
+	case "2":
+		return Palette256
+	case "3":
+		return TTY
+	default:
+		if level := detectColorLevel(true, getenv); level != NoColorLevel {
+			return TTYContextWithDepth(level)
+		}
+		return TTY
+	}
+
+	if getenv("NO_COLOR") != "" {
+		return MonoColor
+	}
+	return TTYContextWithDepth(detectColorLevel(isTerminal, getenv))
+}
@@ -0,0 +1,285 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBasic16ColorName(t *testing.T) {
+	cases := []struct {
+		color    Color
+		expected ColorName
+	}{
+		{RGBColor(0xFF0000), ColorName{Foreground: "1;31"}},
+		{RGBColor(0x00FF00), ColorName{Foreground: "1;32"}},
+		{RGBColor(0x0000FF), ColorName{Foreground: "34"}},
+		{RGBColor(0x800000), ColorName{Foreground: "31"}},
+		{RGBColor(0x808080), ColorName{Foreground: "1;30"}},
+		{RGBColor(0x008080), ColorName{Foreground: "36"}},
+		{RGBColor(0xFF0000).AsBackground(), ColorName{Background: "101"}},
+		{MixColors(RGBColor(0x000000), RGBColor(0xCDCD00)), ColorName{Foreground: "30", Background: "43"}},
+		{NoColor, ColorName{}},
+	}
+	for i := 0; i < len(cases); i++ {
+		if name := Basic16ColorName(cases[i].color); name != cases[i].expected {
+			t.Errorf("%v: expected %+v but received %+v", cases[i].color, cases[i].expected, name)
+		}
+	}
+}
+
+func TestBasic16Context(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := CWrite(builder, CFormat(NoColor, "%v b", CContent(Red, "a")), Basic16); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\033[1;31ma\033[0m b"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestDetectColorLevel(t *testing.T) {
+	cases := []struct {
+		isTerminal bool
+		env        map[string]string
+		expected   ColorLevel
+	}{
+		{true, map[string]string{"COLORTERM": "truecolor"}, TrueColorLevel},
+		{true, map[string]string{"COLORTERM": "24bit", "TERM": "xterm"}, TrueColorLevel},
+		{true, map[string]string{"WT_SESSION": "1"}, TrueColorLevel},
+		{true, map[string]string{"TERM": "xterm-256color"}, Palette256Level},
+		{true, map[string]string{"TERM": "xterm"}, Basic16Level},
+		{true, map[string]string{}, Basic16Level},
+		{true, map[string]string{"TERM": "dumb"}, NoColorLevel},
+		{false, map[string]string{"COLORTERM": "truecolor"}, NoColorLevel},
+	}
+	for i := 0; i < len(cases); i++ {
+		env := cases[i].env
+		getenv := func(name string) string { return env[name] }
+		if level := detectColorLevel(cases[i].isTerminal, getenv); level != cases[i].expected {
+			t.Errorf("%v(terminal: %v): expected %v but received %v", env, cases[i].isTerminal, cases[i].expected, level)
+		}
+	}
+}
+
+func TestGetBestContext(t *testing.T) {
+	t.Setenv("WT_SESSION", "")
+	w := &fakeTerminalWriter{isTerminal: true}
+
+	t.Setenv("COLORTERM", "truecolor")
+	if ctx := GetBestContext(w); ctx != TTY {
+		t.Errorf("expected TTY but received %s", ctx.Name())
+	}
+
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm-256color")
+	if ctx := GetBestContext(w); ctx != Palette256 {
+		t.Errorf("expected Palette256 but received %s", ctx.Name())
+	}
+
+	t.Setenv("TERM", "xterm")
+	if ctx := GetBestContext(w); ctx != Basic16 {
+		t.Errorf("expected Basic16 but received %s", ctx.Name())
+	}
+
+	if ctx := GetBestContext(&fakeTerminalWriter{isTerminal: false}); ctx != MonoColor {
+		t.Errorf("expected MonoColor but received %s", ctx.Name())
+	}
+}
+
+func TestPalette256ColorName(t *testing.T) {
+	cases := []struct {
+		color    Color
+		expected ColorName
+	}{
+		{Red, ColorName{Foreground: "38;5;196"}},
+		{RGBColor(0x0000FF), ColorName{Foreground: "38;5;21"}},
+		{White, ColorName{Foreground: "38;5;231"}},
+		{RGBColor(0x808080), ColorName{Foreground: "38;5;244"}},
+		{MixColors(Black, Red), ColorName{Foreground: "38;5;16", Background: "48;5;196"}},
+		{NoColor, ColorName{}},
+	}
+	for i := 0; i < len(cases); i++ {
+		if name := Palette256ColorName(cases[i].color); name != cases[i].expected {
+			t.Errorf("%d: expected %+v but received %+v", i, cases[i].expected, name)
+		}
+	}
+
+	builder := &strings.Builder{}
+	if err := CWrite(builder, CContent(Red, "a"), Palette256); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\033[38;5;196ma\033[0m"; builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestColorContextFromEnv(t *testing.T) {
+	cases := []struct {
+		isTerminal bool
+		env        map[string]string
+		expected   ColorContext
+	}{
+		{true, map[string]string{"COLORTERM": "truecolor"}, TTY},
+		{false, map[string]string{"COLORTERM": "truecolor"}, MonoColor},
+		// NO_COLOR beat terminal detection
+		{true, map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"}, MonoColor},
+		// FORCE_COLOR beat NO_COLOR and work for pipes
+		{false, map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, Basic16},
+		{false, map[string]string{"FORCE_COLOR": "2"}, Palette256},
+		{false, map[string]string{"FORCE_COLOR": "3"}, TTY},
+		{false, map[string]string{"FORCE_COLOR": "true", "TERM": "xterm-256color"}, Palette256},
+		{false, map[string]string{"FORCE_COLOR": "yes"}, Basic16},
+		{false, map[string]string{"FORCE_COLOR": "yes", "TERM": "dumb"}, TTY},
+		// disabled FORCE_COLOR is ignored
+		{true, map[string]string{"FORCE_COLOR": "0", "TERM": "xterm"}, Basic16},
+		{true, map[string]string{"FORCE_COLOR": "false", "NO_COLOR": "1"}, MonoColor},
+	}
+	for i := 0; i < len(cases); i++ {
+		env := cases[i].env
+		getenv := func(name string) string { return env[name] }
+		if ctx := colorContextFromEnv(cases[i].isTerminal, getenv); ctx != cases[i].expected {
+			t.Errorf("%v(terminal: %v): expected %s but received %s", env, cases[i].isTerminal,
+				cases[i].expected.Name(), ctx.Name())
+		}
+	}
+}
+
+func TestColorContextFromEnvForWriter(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("COLORTERM", "truecolor")
+	if ctx := ColorContextFromEnv(&fakeTerminalWriter{isTerminal: true}); ctx != MonoColor {
+		t.Errorf("expected MonoColor but received %s", ctx.Name())
+	}
+
+	t.Setenv("FORCE_COLOR", "3")
+	if ctx := ColorContextFromEnv(&strings.Builder{}); ctx != TTY {
+		t.Errorf("expected TTY but received %s", ctx.Name())
+	}
+}
+
+func TestNearestBasic16(t *testing.T) {
+	for i := 0; i < len(ansiBasicColors); i++ {
+		if index := RGBColor(ansiBasicColors[i]).NearestBasic16(); int(index) != i {
+			t.Errorf("%s: expected %d but received %d", ansiBasicColors[i], i, index)
+		}
+	}
+	cases := []struct {
+		color    RGBColor
+		expected uint8
+	}{
+		{0x0000FF, 4},
+		{0x808080, 8},
+		{0xFAFAFA, 15},
+		{0x100000, 0},
+	}
+	for i := 0; i < len(cases); i++ {
+		if index := cases[i].color.NearestBasic16(); index != cases[i].expected {
+			t.Errorf("%06X: expected %d but received %d", uint32(cases[i].color), cases[i].expected, index)
+		}
+	}
+	if index := RGBColor(0xFF0000).Toggle().NearestBasic16(); index != 9 {
+		t.Errorf("expected coverage to be ignored but received %d", index)
+	}
+}
+
+func TestNearestPalette256(t *testing.T) {
+	// every color of the cube and the gray ramp is its own nearest color
+	for i := 16; i < 256; i++ {
+		code := ANSIPaletteColor(uint8(i))
+		if index := RGBColor(code).NearestPalette256(); int(index) != i {
+			t.Errorf("%s: expected %d but received %d", code, i, index)
+		}
+	}
+	// standard colors are never returned
+	for i := 0; i < len(ansiBasicColors); i++ {
+		if index := RGBColor(ansiBasicColors[i]).NearestPalette256(); index < 16 {
+			t.Errorf("%s: expected an index of the cube or the gray ramp but received %d", ansiBasicColors[i], index)
+		}
+	}
+	cases := []struct {
+		color    RGBColor
+		expected uint8
+	}{
+		{0x000000, 16},
+		{0xFE0101, 196},
+		{0x7F7F7F, 244},
+		{0x5F87AF, 67},
+	}
+	for i := 0; i < len(cases); i++ {
+		if index := cases[i].color.NearestPalette256(); index != cases[i].expected {
+			t.Errorf("%06X: expected %d but received %d", uint32(cases[i].color), cases[i].expected, index)
+		}
+	}
+}
+
+func TestTTYContextWithDepth(t *testing.T) {
+	color := MixColors(RGBColor(0xFF0000), RGBColor(0x0000FF))
+	cases := []struct {
+		depth    ColorLevel
+		context  ColorContext
+		expected ColorName
+	}{
+		{TrueColorLevel, TTY, ColorName{Foreground: "38;2;255;0;0", Background: "48;2;0;0;255"}},
+		{Palette256Level, Palette256, ColorName{Foreground: "38;5;196", Background: "48;5;21"}},
+		{Basic16Level, Basic16, ColorName{Foreground: "1;31", Background: "44"}},
+		{NoColorLevel, MonoColor, ColorName{}},
+	}
+	for i := 0; i < len(cases); i++ {
+		ctx := TTYContextWithDepth(cases[i].depth)
+		if ctx != cases[i].context {
+			t.Errorf("%v: expected %s but received %s", cases[i].depth, cases[i].context.Name(), ctx.Name())
+		}
+		if level := ctx.ColorLevel(); level != cases[i].depth {
+			t.Errorf("%s: expected level %v but received %v", ctx.Name(), cases[i].depth, level)
+		}
+		if name := ctx.TerminalColorName(color); name != cases[i].expected {
+			t.Errorf("%s: expected %+v but received %+v", ctx.Name(), cases[i].expected, name)
+		}
+
+		// the context write the same color names that it report
+		builder := &strings.Builder{}
+		if err := CWrite(builder, CContent(color, "a"), ctx); err != nil {
+			t.Fatal(err)
+		}
+		expected := "a"
+		if !cases[i].expected.IsEmpty() {
+			expected = "\033[" + cases[i].expected.Foreground + "m\033[" + cases[i].expected.Background + "ma\033[0m"
+		}
+		if builder.String() != expected {
+			t.Errorf("%s: expected %q but received %q", ctx.Name(), expected, builder.String())
+		}
+	}
+
+	if name := LineTTY.TerminalColorName(Red); name != TTY.TerminalColorName(Red) {
+		t.Errorf("expected LineTTY to use true colors but received %+v", name)
+	}
+	if name := Basic16Context(false).TerminalColorName(Red); !name.IsEmpty() {
+		t.Errorf("expected a disabled context to have no color name but received %+v", name)
+	}
+}
+
+func TestWindowsTerminalColorLevel(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "")
+	t.Setenv("WT_SESSION", "b3a4f0c2-6b1e-4a8f-9f3c-2d1e5f6a7b8c")
+
+	// Windows Terminal does not set COLORTERM, but always support true colors
+	t.Setenv("TERM", "xterm")
+	if ctx := GetBestContext(&fakeTerminalWriter{isTerminal: true}); ctx != TTY {
+		t.Errorf("expected TTY but received %s", ctx.Name())
+	}
+	if ctx := ColorContextFromEnv(&fakeTerminalWriter{isTerminal: true}); ctx != TTY {
+		t.Errorf("expected TTY but received %s", ctx.Name())
+	}
+	if ctx := GetBestContext(&fakeTerminalWriter{isTerminal: false}); ctx != MonoColor {
+		t.Errorf("expected WT_SESSION to be ignored for a non-terminal but received %s", ctx.Name())
+	}
+
+	t.Setenv("WT_SESSION", "")
+	if ctx := GetBestContext(&fakeTerminalWriter{isTerminal: true}); ctx != Basic16 {
+		t.Errorf("expected Basic16 without WT_SESSION but received %s", ctx.Name())
+	}
+}
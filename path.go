@@ -1,9 +1,18 @@
 package helpers
 
 import (
+	"bytes"
 	"os"
 )
 
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// StripBOM Remove UTF-8 byte order mark from start of `b`.
+// Other byte order marks(e.g. UTF-16) are not supported and will be left intact
+func StripBOM(b []byte) []byte {
+	return bytes.TrimPrefix(b, utf8BOM)
+}
+
 type Path string
 
 func (this Path) Stat() (os.FileInfo, error) {
@@ -38,6 +47,15 @@ func (this Path) IsSymlink() bool {
 	return (stat.Mode() & os.ModeSymlink) != 0
 }
 
+// ReadFileStripBOM Read content of the file and remove its UTF-8 byte order mark
+func (this Path) ReadFileStripBOM() ([]byte, error) {
+	content, err := os.ReadFile(string(this))
+	if err != nil {
+		return nil, err
+	}
+	return StripBOM(content), nil
+}
+
 func PathExists(path string) bool    { return Path(path).Exists() }
 func PathIsDir(path string) bool     { return Path(path).IsDir() }
 func PathIsFile(path string) bool    { return Path(path).IsFile() }
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripBOM(t *testing.T) {
+	cases := []struct {
+		input    []byte
+		expected []byte
+	}{
+		{[]byte("\xEF\xBB\xBFkey: value"), []byte("key: value")},
+		{[]byte("key: value"), []byte("key: value")},
+		{[]byte("\xEF\xBB\xBF"), []byte{}},
+		{[]byte{}, []byte{}},
+		// only a leading BOM is removed
+		{[]byte("a\xEF\xBB\xBF"), []byte("a\xEF\xBB\xBF")},
+		// UTF-16 byte order marks are not supported and must be left intact
+		{[]byte("\xFF\xFEk\x00"), []byte("\xFF\xFEk\x00")},
+		{[]byte("\xFE\xFF\x00k"), []byte("\xFE\xFF\x00k")},
+	}
+	for i := 0; i < len(cases); i++ {
+		if result := StripBOM(cases[i].input); !bytes.Equal(result, cases[i].expected) {
+			t.Errorf("StripBOM(%q): expected %q but received %q", cases[i].input, cases[i].expected, result)
+		}
+	}
+}
+
+func TestPathReadFileStripBOM(t *testing.T) {
+	dir := t.TempDir()
+	withBOM := filepath.Join(dir, "with-bom.txt")
+	if err := os.WriteFile(withBOM, []byte("\xEF\xBB\xBFcontent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content, err := Path(withBOM).ReadFileStripBOM()
+	if err != nil || string(content) != "content" {
+		t.Errorf("expected %q but received %q(%v)", "content", content, err)
+	}
+
+	if _, err = Path(filepath.Join(dir, "missing.txt")).ReadFileStripBOM(); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error but received %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	minPooledBufferChunk = 64
+
+	ErrBufferAllocationFailed = StringError("Failed to allocate buffer")
+)
+
+// PooledBuffer a growable buffer similar to `bytes.Buffer` that get its memory from a `BufferManager`.
+// Memory of the buffer will be returned to the manager on `Reset` or `Close`
+type PooledBuffer struct {
+	manager    BufferManager
+	chunks     []Buffer
+	lastUsed   int // used bytes of the last chunk
+	size       int // total written bytes
+	readChunk  int
+	readPos    int // read position in `readChunk`
+	readOffset int // total read bytes
+}
+
+// NewPooledBuffer Create a `PooledBuffer` that allocate its memory from `manager`
+func NewPooledBuffer(manager BufferManager) *PooledBuffer {
+	if manager == nil {
+		panic("Invalid argument")
+	}
+	return &PooledBuffer{manager: manager}
+}
+
+func (this *PooledBuffer) grow(required int) error {
+	chunkSize := minPooledBufferChunk
+	if n := len(this.chunks); n != 0 {
+		chunkSize = this.chunks[n-1].GetSize() * 2
+	}
+	if chunkSize < required {
+		chunkSize = required
+	}
+	if bucketSize := this.manager.GetBucketSize(); chunkSize > bucketSize {
+		chunkSize = bucketSize
+	}
+
+	chunk := this.manager.Allocate(chunkSize)
+	if chunk == nil {
+		return ErrBufferAllocationFailed
+	}
+	this.chunks = append(this.chunks, chunk)
+	this.lastUsed = 0
+	return nil
+}
+
+func (this *PooledBuffer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) != 0 {
+		n := len(this.chunks)
+		if n == 0 || this.lastUsed == this.chunks[n-1].GetSize() {
+			if err := this.grow(len(p)); err != nil {
+				return written, err
+			}
+			n = len(this.chunks)
+		}
+
+		copied := copy(this.chunks[n-1].GetData()[this.lastUsed:], p)
+		this.lastUsed += copied
+		this.size += copied
+		written += copied
+		p = p[copied:]
+	}
+	return written, nil
+}
+func (this *PooledBuffer) WriteString(s string) (int, error) { return this.Write([]byte(s)) }
+
+func (this *PooledBuffer) Read(p []byte) (int, error) {
+	if this.readOffset >= this.size {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	read := 0
+	for len(p) != 0 && this.readOffset < this.size {
+		chunkEnd := this.chunks[this.readChunk].GetSize()
+		if this.readChunk == len(this.chunks)-1 {
+			chunkEnd = this.lastUsed
+		}
+		if this.readPos == chunkEnd {
+			this.readChunk++
+			this.readPos = 0
+			continue
+		}
+
+		copied := copy(p, this.chunks[this.readChunk].GetData()[this.readPos:chunkEnd])
+		this.readPos += copied
+		this.readOffset += copied
+		read += copied
+		p = p[copied:]
+	}
+	return read, nil
+}
+
+// Len Number of unread bytes of the buffer
+func (this *PooledBuffer) Len() int { return this.size - this.readOffset }
+
+// Bytes Return unread content of the buffer. If content is stored in multiple chunks, it will be copied
+func (this *PooledBuffer) Bytes() []byte {
+	if this.Len() == 0 {
+		return []byte{}
+	}
+	if len(this.chunks) == 1 {
+		return this.chunks[0].GetData()[this.readPos:this.lastUsed]
+	}
+
+	result := make([]byte, 0, this.Len())
+	for i := this.readChunk; i < len(this.chunks); i++ {
+		data := this.chunks[i].GetData()
+		if i == len(this.chunks)-1 {
+			data = data[:this.lastUsed]
+		}
+		if i == this.readChunk {
+			data = data[this.readPos:]
+		}
+		result = append(result, data...)
+	}
+	return result
+}
+func (this *PooledBuffer) String() string { return string(this.Bytes()) }
+
+// Reset Release all memory of this buffer to the manager
+func (this *PooledBuffer) Reset() {
+	for i := 0; i < len(this.chunks); i++ {
+		this.manager.Free(this.chunks[i])
+	}
+	this.chunks = nil
+	this.lastUsed = 0
+	this.size = 0
+	this.readChunk = 0
+	this.readPos = 0
+	this.readOffset = 0
+}
+func (this *PooledBuffer) Close() error {
+	this.Reset()
+	return nil
+}
+
+// MarshalJSONPooled Encode `v` as JSON(same as `json.Marshal`) into a buffer that allocated from `manager`.
+// Caller must free the returned buffer using `manager` after use. Encoded value must fit in a bucket of `manager`
+func MarshalJSONPooled(manager BufferManager, v interface{}) (Buffer, error) {
+	encoded := NewPooledBuffer(manager)
+	defer encoded.Close()
+
+	if err := json.NewEncoder(encoded).Encode(v); err != nil {
+		return nil, err
+	}
+
+	// `Encoder` terminate each value with a newline that `json.Marshal` does not write
+	size := encoded.Len() - 1
+	if size > manager.GetBucketSize() {
+		return nil, ErrBufferTooLarge
+	}
+	result := manager.Allocate(size)
+	if result == nil {
+		return nil, ErrBufferAllocationFailed
+	}
+	if _, err := io.ReadFull(encoded, result.GetData()[:size]); err != nil {
+		manager.Free(result)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadFullPooled Allocate a buffer of `size` bytes from `manager` and fill it from `r`. On a short read the
+// buffer is freed and the error(e.g. `io.ErrUnexpectedEOF`) is returned
+func ReadFullPooled(manager BufferManager, r io.Reader, size int) (Buffer, error) {
+	if size > manager.GetBucketSize() {
+		return nil, ErrBufferTooLarge
+	}
+	result := manager.Allocate(size)
+	if result == nil {
+		return nil, ErrBufferAllocationFailed
+	}
+
+	if _, err := io.ReadFull(r, result.GetData()[:size]); err != nil {
+		manager.Free(result)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestPooledBufferMirrorBytesBuffer(t *testing.T) {
+	manager := NewBufferManager(256, 1, 4)
+	pooled := NewPooledBuffer(manager)
+	defer pooled.Close()
+	expected := &bytes.Buffer{}
+
+	// write enough data to span multiple chunks
+	for i := 0; i < 50; i++ {
+		s := strings.Repeat(string(rune('a'+i%26)), i)
+		pooled.WriteString(s)
+		expected.WriteString(s)
+	}
+	if pooled.Len() != expected.Len() {
+		t.Fatalf("expected %d bytes but received %d", expected.Len(), pooled.Len())
+	}
+	if !bytes.Equal(pooled.Bytes(), expected.Bytes()) {
+		t.Errorf("expected %q but received %q", expected.String(), pooled.String())
+	}
+
+	p1, p2 := make([]byte, 100), make([]byte, 100)
+	n1, err1 := pooled.Read(p1)
+	n2, err2 := expected.Read(p2)
+	if n1 != n2 || err1 != err2 || !bytes.Equal(p1[:n1], p2[:n2]) {
+		t.Errorf("expected %q(%v) but received %q(%v)", p2[:n2], err2, p1[:n1], err1)
+	}
+	if pooled.Len() != expected.Len() || !bytes.Equal(pooled.Bytes(), expected.Bytes()) {
+		t.Errorf("expected unread content %q but received %q", expected.String(), pooled.String())
+	}
+
+	rest, err := ioutil.ReadAll(pooled)
+	if err != nil || !bytes.Equal(rest, expected.Bytes()) {
+		t.Errorf("expected %q but received %q(%v)", expected.String(), rest, err)
+	}
+	if n, err := pooled.Read(p1); n != 0 || err != io.EOF {
+		t.Errorf("expected EOF but received %d, %v", n, err)
+	}
+	if pooled.Len() != 0 || len(pooled.Bytes()) != 0 {
+		t.Errorf("expected an empty buffer but received %q", pooled.String())
+	}
+}
+
+func TestPooledBufferReleaseMemory(t *testing.T) {
+	manager := NewBufferManager(256, 1, 4)
+	pooled := NewPooledBuffer(manager)
+	pooled.Write(make([]byte, 1000))
+	if stats := manager.GetStats(); stats.AllocatedBuffers == 0 || stats.AllocatedBytes < 1000 {
+		t.Errorf("expected at least 1000 allocated bytes but received %d", stats.AllocatedBytes)
+	}
+
+	pooled.Reset()
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 {
+		t.Errorf("expected no allocated buffer after reset but received %+v", stats)
+	}
+
+	pooled.WriteString("reused")
+	if pooled.String() != "reused" {
+		t.Errorf("expected %q but received %q", "reused", pooled.String())
+	}
+	pooled.Close()
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 {
+		t.Errorf("expected no allocated buffer after close but received %+v", stats)
+	}
+}
+
+func TestMarshalJSONPooled(t *testing.T) {
+	manager := NewBufferManager(64, 1, 4)
+	value := struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}{"a<b>", 3, []string{"x", "y"}}
+
+	expected, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := MarshalJSONPooled(manager, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.GetData(), expected) {
+		t.Errorf("expected %q but received %q", expected, buf.GetData())
+	}
+
+	manager.Free(buf)
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 {
+		t.Errorf("expected all buffers to be freed but received %+v", stats)
+	}
+}
+
+func TestMarshalJSONPooledErrors(t *testing.T) {
+	manager := NewBufferManager(16, 1, 4)
+	if _, err := MarshalJSONPooled(manager, strings.Repeat("a", 32)); err != ErrBufferTooLarge {
+		t.Errorf("expected %v but received %v", ErrBufferTooLarge, err)
+	}
+	if _, err := MarshalJSONPooled(manager, make(chan int)); err == nil {
+		t.Errorf("expected an error for a value that can't be encoded")
+	}
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 {
+		t.Errorf("expected no buffer to be leaked but received %+v", stats)
+	}
+}
+
+func TestReadFullPooled(t *testing.T) {
+	manager := NewBufferManager(64, 1, 4)
+	r := strings.NewReader("frame1frame2")
+
+	buf, err := ReadFullPooled(manager, r, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf.GetData()) != "frame1" {
+		t.Errorf("expected %q but received %q", "frame1", buf.GetData())
+	}
+	manager.Free(buf)
+
+	if _, err = ReadFullPooled(manager, r, 10); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v but received %v", io.ErrUnexpectedEOF, err)
+	}
+	if _, err = ReadFullPooled(manager, r, 1); err != io.EOF {
+		t.Errorf("expected %v but received %v", io.EOF, err)
+	}
+	if _, err = ReadFullPooled(manager, r, 65); err != ErrBufferTooLarge {
+		t.Errorf("expected %v but received %v", ErrBufferTooLarge, err)
+	}
+	if stats := manager.GetStats(); stats.AllocatedBuffers != 0 || stats.AllocatedBytes != 0 {
+		t.Errorf("expected all buffers to be freed but received %+v", stats)
+	}
+}
@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	ProgressBarFilledCell = "#"
+	ProgressBarEmptyCell  = "-"
+
+	defaultProgressBarWidth = 80
+	progressBarSuffixFormat = " %3d%%"
+	progressBarSuffixWidth  = 5
+)
+
+// RenderProgressBar draw a bar of `width` cells that `fraction` of them are filled with `filled` color and
+// the rest of them with `empty` color, followed by the percentage.
+// If `width` is not positive, it will be calculated from width of the terminal(or 80 if `w` is not a terminal)
+func RenderProgressBar(w io.Writer, context ColorContext, fraction float64, width int, filled, empty Color) error {
+	if context == nil {
+		context = GetDefaultContext(w)
+	}
+	if filled == nil {
+		filled = NoColor
+	}
+	if empty == nil {
+		empty = NoColor
+	}
+	if math.IsNaN(fraction) || fraction < 0 {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+	if width <= 0 {
+		width = defaultProgressBarWidth
+		if f, ok := w.(*os.File); ok && IsTerminal(f) {
+			if termWidth, _, err := GetTerminalSize(f); err == nil && termWidth > 0 {
+				width = termWidth
+			}
+		}
+		width = IIFn(width > progressBarSuffixWidth, width-progressBarSuffixWidth, 1)
+	}
+
+	filledCells := int(math.Round(fraction * float64(width)))
+	cw := NewColoredWriter(context, w)
+	if filledCells != 0 {
+		if err := cw.WriteContent(CContent(filled, strings.Repeat(ProgressBarFilledCell, filledCells))); err != nil {
+			return err
+		}
+	}
+	if filledCells != width {
+		if err := cw.WriteContent(CContent(empty, strings.Repeat(ProgressBarEmptyCell, width-filledCells))); err != nil {
+			return err
+		}
+	}
+	return cw.WriteString(fmt.Sprintf(progressBarSuffixFormat, int(math.Round(fraction*100))))
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderProgressBar(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		filled   int
+		suffix   string
+	}{
+		{0, 0, "   0%"},
+		{0.25, 5, "  25%"},
+		{0.5, 10, "  50%"},
+		{0.333, 7, "  33%"},
+		{0.996, 20, " 100%"},
+		{1, 20, " 100%"},
+		{-1, 0, "   0%"},
+		{2, 20, " 100%"},
+	}
+	for i := 0; i < len(tests); i++ {
+		builder := &strings.Builder{}
+		if err := RenderProgressBar(builder, MonoColor, tests[i].fraction, 20, Green, Red); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := strings.Repeat(ProgressBarFilledCell, tests[i].filled) +
+			strings.Repeat(ProgressBarEmptyCell, 20-tests[i].filled) + tests[i].suffix
+		if builder.String() != expected {
+			t.Errorf("%v: expected %q but received %q", tests[i].fraction, expected, builder.String())
+		}
+	}
+}
+
+func TestRenderProgressBarColors(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := RenderProgressBar(builder, TTY, 0.5, 4, Green, Red); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\033[38;2;0;128;0m##\033[0m\033[38;2;255;0;0m--\033[0m  50%"
+	if builder.String() != expected {
+		t.Errorf("expected %q but received %q", expected, builder.String())
+	}
+}
+
+func TestRenderProgressBarDefaultWidth(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := RenderProgressBar(builder, MonoColor, 1, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(builder.String()) != defaultProgressBarWidth {
+		t.Errorf("expected a bar of %d cells but received %q", defaultProgressBarWidth, builder.String())
+	}
+}
@@ -0,0 +1,304 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// logRateLimiter a token bucket that allow at most `limit` records in each `interval`
+type logRateLimiter struct {
+	lock     sync.Mutex
+	limit    int
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+	dropped  int
+	now      func() time.Time
+	// flushing is true while a ticker is reporting the dropped records, `flushed` is closed when it stop
+	flushing bool
+	flushed  chan struct{}
+	closed   bool
+	stop     chan struct{}
+	report   func(dropped int)
+}
+
+func newLogRateLimiter(limit int, interval time.Duration, report func(dropped int)) *logRateLimiter {
+	return &logRateLimiter{
+		limit:    limit,
+		interval: interval,
+		tokens:   float64(limit),
+		last:     time.Now(),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		report:   report,
+	}
+}
+
+// take Try to take a token from the bucket, if it was successful return number of records that dropped
+// since the last successful call
+func (this *logRateLimiter) take() (ok bool, dropped int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	now := this.now()
+	if elapsed := now.Sub(this.last); elapsed > 0 {
+		this.tokens += float64(this.limit) * float64(elapsed) / float64(this.interval)
+		if this.tokens > float64(this.limit) {
+			this.tokens = float64(this.limit)
+		}
+	}
+	this.last = now
+
+	if this.tokens < 1 {
+		this.dropped += 1
+		if !this.flushing && !this.closed {
+			this.flushing = true
+			this.flushed = make(chan struct{})
+			go this.flushPeriodically(this.flushed)
+		}
+		return false, 0
+	}
+	this.tokens -= 1
+	dropped, this.dropped = this.dropped, 0
+	return true, dropped
+}
+func (this *logRateLimiter) takeDropped() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	dropped := this.dropped
+	this.dropped = 0
+	return dropped
+}
+
+// close Stop the ticker, if there is any, and wait for it to exit. No ticker is started after this
+func (this *logRateLimiter) close() {
+	this.lock.Lock()
+	if this.closed {
+		this.lock.Unlock()
+		return
+	}
+	this.closed = true
+	close(this.stop)
+	flushed := this.flushed
+	this.lock.Unlock()
+
+	if flushed != nil {
+		<-flushed
+	}
+}
+
+// flushPeriodically Report dropped records every `interval` and stop as soon as an interval pass without any
+// dropped record, so an idle logger does not keep a ticker
+func (this *logRateLimiter) flushPeriodically(flushed chan struct{}) {
+	ticker := time.NewTicker(this.interval)
+	defer func() {
+		ticker.Stop()
+		close(flushed)
+	}()
+
+	for {
+		select {
+		case <-this.stop:
+			this.lock.Lock()
+			this.flushing = false
+			this.lock.Unlock()
+			return
+		case <-ticker.C:
+		}
+
+		this.lock.Lock()
+		dropped := this.dropped
+		this.dropped = 0
+		if dropped == 0 {
+			this.flushing = false
+		}
+		this.lock.Unlock()
+
+		if dropped == 0 {
+			return
+		}
+		this.report(dropped)
+	}
+}
+
+// RateLimitedLogger a `Logger` that write at most `limit` records in each `interval` to its inner logger and
+// drop the rest. Number of the dropped records is reported before the next record that is written, every
+// `interval` while records are dropped, or when `Flush` is called. The report is logged as a warning, or at the
+// minimum level of the inner logger if warnings are disabled. Loggers that are created using `WithContext`
+// share the limit of this logger, while loggers that are created using `CreateLogger` have their own limit
+type RateLimitedLogger struct {
+	inner   Logger
+	limiter *logRateLimiter
+}
+
+// NewRateLimitedLogger Create a `RateLimitedLogger` that write at most `limit` records in each `interval`
+// to `inner`
+func NewRateLimitedLogger(inner Logger, limit int, interval time.Duration) *RateLimitedLogger {
+	if inner == nil || limit <= 0 || interval <= 0 {
+		panic("Invalid argument")
+	}
+
+	result := &RateLimitedLogger{inner: inner}
+	result.limiter = newLogRateLimiter(limit, interval, result.reportDropped)
+	return result
+}
+
+func (this *RateLimitedLogger) reportDropped(dropped int) {
+	if dropped == 0 {
+		return
+	}
+
+	// report must never be filtered, so use minimum level of the inner logger if warnings are disabled
+	level := Warn
+	if minimumLevel := this.inner.GetMinimumLevel(); minimumLevel > level {
+		level = minimumLevel
+	}
+	switch level {
+	case Error:
+		this.inner.Errorf("dropped %d messages", dropped)
+	case Fatal:
+		this.inner.Fatalf("dropped %d messages", dropped)
+	default:
+		this.inner.Warnf("dropped %d messages", dropped)
+	}
+}
+func (this *RateLimitedLogger) allow(level LogLevel) bool {
+	if !this.inner.IsEnabled(level) {
+		return false
+	}
+
+	ok, dropped := this.limiter.take()
+	if ok {
+		this.reportDropped(dropped)
+	}
+	return ok
+}
+func (this *RateLimitedLogger) allowVerbose(verbosityLevel int) bool {
+	return verbosityLevel <= this.inner.GetVerbosityLevel() && this.allow(Info)
+}
+
+// Flush Report number of the records that dropped since the last written record, if there is any
+func (this *RateLimitedLogger) Flush() {
+	this.reportDropped(this.limiter.takeDropped())
+}
+
+// Close Stop reporting dropped records periodically and report the final count. Loggers that are created
+// using `WithContext` share the ticker of this logger and are closed too. It must be called before the
+// factory of the inner logger is closed
+func (this *RateLimitedLogger) Close() error {
+	this.limiter.close()
+	this.Flush()
+	return nil
+}
+
+func (this *RateLimitedLogger) GetInner() Logger          { return this.inner }
+func (this *RateLimitedLogger) GetName() string           { return this.inner.GetName() }
+func (this *RateLimitedLogger) GetLogFactory() LogFactory { return this.inner.GetLogFactory() }
+func (this *RateLimitedLogger) GetMinimumLevel() LogLevel { return this.inner.GetMinimumLevel() }
+func (this *RateLimitedLogger) GetVerbosityLevel() int    { return this.inner.GetVerbosityLevel() }
+func (this *RateLimitedLogger) CreateLogger(name string, level *LogLevel, verbosityLevel *int) Logger {
+	return NewRateLimitedLogger(
+		this.inner.CreateLogger(name, level, verbosityLevel),
+		this.limiter.limit,
+		this.limiter.interval)
+}
+func (this *RateLimitedLogger) WithContext(ctx context.Context) Logger {
+	return &RateLimitedLogger{
+		inner:   this.inner.WithContext(ctx),
+		limiter: this.limiter,
+	}
+}
+func (this *RateLimitedLogger) V(verbosityLevel int) bool     { return this.inner.V(verbosityLevel) }
+func (this *RateLimitedLogger) IsEnabled(level LogLevel) bool { return this.inner.IsEnabled(level) }
+func (this *RateLimitedLogger) Debug(message interface{}) {
+	if this.allow(Debug) {
+		this.inner.Debug(message)
+	}
+}
+func (this *RateLimitedLogger) Debugf(format string, args ...interface{}) {
+	if this.allow(Debug) {
+		this.inner.Debugf(format, args...)
+	}
+}
+func (this *RateLimitedLogger) Info(message interface{}) {
+	if this.allow(Info) {
+		this.inner.Info(message)
+	}
+}
+func (this *RateLimitedLogger) Infof(format string, args ...interface{}) {
+	if this.allow(Info) {
+		this.inner.Infof(format, args...)
+	}
+}
+func (this *RateLimitedLogger) Warn(message interface{}) {
+	if this.allow(Warn) {
+		this.inner.Warn(message)
+	}
+}
+func (this *RateLimitedLogger) Warnf(format string, args ...interface{}) {
+	if this.allow(Warn) {
+		this.inner.Warnf(format, args...)
+	}
+}
+func (this *RateLimitedLogger) Error(message interface{}) {
+	if this.allow(Error) {
+		this.inner.Error(message)
+	}
+}
+func (this *RateLimitedLogger) Errorf(format string, args ...interface{}) {
+	if this.allow(Error) {
+		this.inner.Errorf(format, args...)
+	}
+}
+func (this *RateLimitedLogger) Fatal(message interface{}) {
+	if this.allow(Fatal) {
+		this.inner.Fatal(message)
+	}
+}
+func (this *RateLimitedLogger) Fatalf(format string, args ...interface{}) {
+	if this.allow(Fatal) {
+		this.inner.Fatalf(format, args...)
+	}
+}
+func (this *RateLimitedLogger) Verbose(verbosityLevel int, message interface{}) {
+	if this.allowVerbose(verbosityLevel) {
+		this.inner.Verbose(verbosityLevel, message)
+	}
+}
+func (this *RateLimitedLogger) Verbosef(verbosityLevel int, format string, args ...interface{}) {
+	if this.allowVerbose(verbosityLevel) {
+		this.inner.Verbosef(verbosityLevel, format, args...)
+	}
+}
+func (this *RateLimitedLogger) DebugLazy(fn func() interface{}) {
+	if this.allow(Debug) {
+		this.inner.DebugLazy(fn)
+	}
+}
+func (this *RateLimitedLogger) InfoLazy(fn func() interface{}) {
+	if this.allow(Info) {
+		this.inner.InfoLazy(fn)
+	}
+}
+func (this *RateLimitedLogger) WarnLazy(fn func() interface{}) {
+	if this.allow(Warn) {
+		this.inner.WarnLazy(fn)
+	}
+}
+func (this *RateLimitedLogger) ErrorLazy(fn func() interface{}) {
+	if this.allow(Error) {
+		this.inner.ErrorLazy(fn)
+	}
+}
+func (this *RateLimitedLogger) FatalLazy(fn func() interface{}) {
+	if this.allow(Fatal) {
+		this.inner.FatalLazy(fn)
+	}
+}
+func (this *RateLimitedLogger) VerboseLazy(verbosityLevel int, fn func() interface{}) {
+	if this.allowVerbose(verbosityLevel) {
+		this.inner.VerboseLazy(verbosityLevel, fn)
+	}
+}
@@ -0,0 +1,140 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitedLoggerCap(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	logger := NewRateLimitedLogger(factory.CreateLogger("test", nil, nil), 5, time.Hour)
+	for i := 0; i < 20; i++ {
+		logger.Infof("message %d", i)
+	}
+	if records := factory.Records(); len(records) != 5 {
+		t.Fatalf("expected 5 records but received %d", len(records))
+	}
+
+	logger.Flush()
+	records := factory.Records()
+	if len(records) != 6 {
+		t.Fatalf("expected a summary record but received %d records", len(records))
+	}
+	if summary := records[5]; summary.Level != Warn || summary.Message() != "dropped 15 messages" {
+		t.Errorf("unexpected summary: %v %q", summary.Level, summary.Message())
+	}
+
+	logger.Flush()
+	if records = factory.Records(); len(records) != 6 {
+		t.Errorf("expected no summary when nothing is dropped but received %d records", len(records))
+	}
+}
+
+func TestRateLimitedLoggerReportBeforeNextRecord(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	logger := NewRateLimitedLogger(factory.CreateLogger("test", nil, nil), 1, time.Hour)
+	now := time.Now()
+	logger.limiter.now = func() time.Time { return now }
+
+	logger.Info("first")
+	logger.Info("dropped")
+	now = now.Add(time.Hour)
+	logger.Info("second")
+
+	records := factory.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records but received %d", len(records))
+	}
+	if records[1].Message() != "dropped 1 messages" || records[2].Message() != "second" {
+		t.Errorf("expected summary before the next record but received %q, %q", records[1].Message(), records[2].Message())
+	}
+}
+
+func TestRateLimitedLoggerSummaryLevel(t *testing.T) {
+	factory := NewMemoryLogFactory(Error, 0)
+	logger := NewRateLimitedLogger(factory.CreateLogger("test", nil, nil), 1, time.Hour)
+	logger.Error("first")
+	logger.Error("dropped")
+	logger.Flush()
+
+	records := factory.Records()
+	if len(records) != 2 {
+		t.Fatalf("expected summary to be logged when warnings are disabled but received %d records", len(records))
+	}
+	if records[1].Level != Error || records[1].Message() != "dropped 1 messages" {
+		t.Errorf("unexpected summary: %v %q", records[1].Level, records[1].Message())
+	}
+}
+
+func TestRateLimitedLoggerPeriodicSummary(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	logger := NewRateLimitedLogger(factory.CreateLogger("test", nil, nil), 1, 20*time.Millisecond)
+	for i := 0; i < 5; i++ {
+		logger.Info("message")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if records := factory.RecordsAt(Warn); len(records) != 0 {
+			if records[0].Message() != "dropped 4 messages" {
+				t.Errorf("unexpected summary %q", records[0].Message())
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("expected summary to be reported without a new record or Flush")
+}
+
+func TestRateLimitedLoggerClose(t *testing.T) {
+	factory := NewMemoryLogFactory(Debug, 0)
+	logger := NewRateLimitedLogger(factory.CreateLogger("test", nil, nil), 1, time.Hour)
+	for i := 0; i < 5; i++ {
+		logger.Info("message")
+	}
+
+	logger.limiter.lock.Lock()
+	flushed := logger.limiter.flushed
+	logger.limiter.lock.Unlock()
+	if flushed == nil {
+		t.Fatal("expected a ticker to be started for the dropped records")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		if err := logger.Close(); err != nil {
+			t.Errorf("expected nil error but received %v", err)
+		}
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return before the end of the interval")
+	}
+	select {
+	case <-flushed:
+	default:
+		t.Error("expected the ticker goroutine to exit")
+	}
+
+	records := factory.Records()
+	if len(records) != 2 || records[1].Message() != "dropped 4 messages" {
+		t.Fatalf("expected the final count to be reported but received %d records", len(records))
+	}
+
+	// records that are dropped after close do not start a new ticker
+	logger.Info("dropped")
+	logger.limiter.lock.Lock()
+	flushing := logger.limiter.flushing
+	logger.limiter.lock.Unlock()
+	if flushing {
+		t.Error("expected no ticker after Close")
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("expected closing twice to succeed but received %v", err)
+	}
+	if records = factory.Records(); len(records) != 3 || records[2].Message() != "dropped 1 messages" {
+		t.Errorf("expected the count of records dropped after Close to be reported but received %d records", len(records))
+	}
+}
@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	// RedactedValue value that replace content of the secret fields
+	RedactedValue = "***"
+
+	logTagName     = "log"
+	logSecretTag   = "secret"
+	maxRedactDepth = 32
+)
+
+func newRedactedFieldSet(fields []string) map[string]struct{} {
+	result := make(map[string]struct{}, len(fields))
+	for i := 0; i < len(fields); i++ {
+		result[strings.ToLower(fields[i])] = struct{}{}
+	}
+	return result
+}
+
+// Redact Replace value of secret fields of `value` with `RedactedValue`.
+// Fields of structs that tagged with `log:"secret"` and struct fields or map keys that their name(case insensitive)
+// is in `fields` are secret. Structs, maps and pointers that contain a secret are copied and keep their types, so
+// secrets that can't hold `RedactedValue`(e.g. a numeric field) are replaced with their zero value. Unexported
+// fields are kept as is. If `value` does not contain any secret, it is returned as is
+func Redact(value interface{}, fields ...string) interface{} {
+	result, _ := redactValue(reflect.ValueOf(value), newRedactedFieldSet(fields), 0)
+	return result
+}
+
+// redactedValueOf Return the value that replace a secret of type `t`
+func redactedValueOf(t reflect.Type) reflect.Value {
+	redacted := reflect.ValueOf(RedactedValue)
+	if redacted.Type().AssignableTo(t) {
+		return redacted
+	}
+	if t.Kind() == reflect.String {
+		return redacted.Convert(t)
+	}
+	return reflect.Zero(t)
+}
+
+func redactValue(v reflect.Value, fields map[string]struct{}, depth int) (interface{}, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+	if redacted, changed := redactReflectValue(v, fields, depth); changed {
+		return redacted.Interface(), true
+	}
+	return v.Interface(), false
+}
+
+// redactReflectValue Return a copy of `v` that its secrets are redacted or `v` itself if it has no secret
+func redactReflectValue(v reflect.Value, fields map[string]struct{}, depth int) (reflect.Value, bool) {
+	if depth > maxRedactDepth || !v.CanInterface() {
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false
+		}
+		inner, changed := redactReflectValue(v.Elem(), fields, depth+1)
+		if !changed {
+			return v, false
+		}
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(inner)
+		return result, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		return redactReflectValue(v.Elem(), fields, depth+1)
+
+	case reflect.Struct:
+		t := v.Type()
+		var result reflect.Value
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			var fieldValue reflect.Value
+			_, isSecret := fields[strings.ToLower(field.Name)]
+			if isSecret || field.Tag.Get(logTagName) == logSecretTag {
+				fieldValue = redactedValueOf(field.Type)
+			} else if redacted, changed := redactReflectValue(v.Field(i), fields, depth+1); changed {
+				fieldValue = redacted
+			} else {
+				continue
+			}
+
+			if !result.IsValid() {
+				// copy the struct, so unexported fields are also kept
+				result = reflect.New(t).Elem()
+				result.Set(v)
+			}
+			result.Field(i).Set(fieldValue)
+		}
+		if result.IsValid() {
+			return result, true
+		}
+		return v, false
+
+	case reflect.Map:
+		t := v.Type()
+		if v.IsNil() || t.Key().Kind() != reflect.String {
+			return v, false
+		}
+
+		changed := false
+		result := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entryValue := iter.Value()
+			if _, isSecret := fields[strings.ToLower(iter.Key().String())]; isSecret {
+				entryValue = redactedValueOf(t.Elem())
+				changed = true
+			} else if redacted, entryChanged := redactReflectValue(entryValue, fields, depth+1); entryChanged {
+				entryValue = redacted
+				changed = true
+			}
+			result.SetMapIndex(iter.Key(), entryValue)
+		}
+		if changed {
+			return result, true
+		}
+		return v, false
+
+	default:
+		return v, false
+	}
+}
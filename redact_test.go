@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+type testCredentials struct {
+	User     string
+	Password string `log:"secret"`
+	PIN      int    `log:"secret"`
+	Token    string
+	internal string
+}
+
+type testRequest struct {
+	Path        string
+	Credentials *testCredentials
+	Headers     map[string]string
+}
+
+func TestRedactKeepTypes(t *testing.T) {
+	creds := testCredentials{User: "user", Password: "pass", PIN: 1234, Token: "token", internal: "internal"}
+	redacted, ok := Redact(creds, "token").(testCredentials)
+	if !ok {
+		t.Fatalf("expected testCredentials but received %T", Redact(creds, "token"))
+	}
+	expected := testCredentials{User: "user", Password: RedactedValue, PIN: 0, Token: RedactedValue, internal: "internal"}
+	if redacted != expected {
+		t.Errorf("expected %+v but received %+v", expected, redacted)
+	}
+	if creds.Password != "pass" || creds.Token != "token" {
+		t.Errorf("expected source value to be unchanged")
+	}
+}
+
+func TestRedactNestedValues(t *testing.T) {
+	req := &testRequest{
+		Path:        "/login",
+		Credentials: &testCredentials{User: "user", Password: "pass"},
+		Headers:     map[string]string{"Authorization": "Bearer x", "Accept": "*/*"},
+	}
+	redacted, ok := Redact(req, "authorization").(*testRequest)
+	if !ok {
+		t.Fatalf("expected *testRequest but received %T", Redact(req, "authorization"))
+	}
+	if redacted == req || redacted.Credentials == req.Credentials {
+		t.Errorf("expected pointers that contain a secret to be copied")
+	}
+	if redacted.Path != "/login" || redacted.Credentials.User != "user" || redacted.Credentials.Password != RedactedValue {
+		t.Errorf("unexpected redacted value %+v %+v", redacted, redacted.Credentials)
+	}
+	if redacted.Headers["Authorization"] != RedactedValue || redacted.Headers["Accept"] != "*/*" {
+		t.Errorf("unexpected redacted headers %v", redacted.Headers)
+	}
+	if req.Credentials.Password != "pass" || req.Headers["Authorization"] != "Bearer x" {
+		t.Errorf("expected source value to be unchanged")
+	}
+}
+
+func TestRedactWithoutSecret(t *testing.T) {
+	req := &testRequest{Path: "/", Headers: map[string]string{"Accept": "*/*"}}
+	if Redact(req, "authorization") != req {
+		t.Errorf("expected a value without a secret to be returned as is")
+	}
+	if Redact(nil) != nil {
+		t.Errorf("expected nil to be returned as is")
+	}
+	if Redact(12) != 12 {
+		t.Errorf("expected a number to be returned as is")
+	}
+}
+
+func TestRedactColoredValueKeepRender(t *testing.T) {
+	value := CContent(Red, testCredentials{User: "user", Password: "pass"})
+	redacted, ok := Redact(value).(ColoredValue)
+	if !ok {
+		t.Fatalf("expected ColoredValue but received %T", Redact(value))
+	}
+
+	builder := &strings.Builder{}
+	if err := NewColoredWriter(MonoColor, builder).WriteContent(redacted); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(builder.String(), "pass") || !strings.Contains(builder.String(), RedactedValue) {
+		t.Errorf("expected secret to be redacted but received %q", builder.String())
+	}
+}
@@ -0,0 +1,75 @@
+package helpers
+
+//region Result
+
+// Result hold result of a function that return `(T, error)`
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// NewResult Create a `Result` from output of a function, e.g. `NewResult(strconv.Atoi(s))`
+func NewResult[T any](value T, err error) Result[T] {
+	if err != nil {
+		var zero T
+		return Result[T]{value: zero, err: err}
+	}
+	return Result[T]{value: value}
+}
+
+// Ok Create a successful `Result`
+func Ok[T any](value T) Result[T] { return Result[T]{value: value} }
+
+// Fail Create a failed `Result`
+func Fail[T any](err error) Result[T] { return Result[T]{err: err} }
+
+// MapResult Convert value of `r` using `fn`, if `r` is failed `fn` will not be called and the error will be kept
+func MapResult[T, U any](r Result[T], fn func(T) (U, error)) Result[U] {
+	if r.err != nil {
+		return Fail[U](r.err)
+	}
+	return NewResult(fn(r.value))
+}
+
+func (this Result[T]) IsOk() bool      { return this.err == nil }
+func (this Result[T]) Err() error      { return this.err }
+func (this Result[T]) Get() (T, error) { return this.value, this.err }
+func (this Result[T]) UnwrapOr(defaultValue T) T {
+	if this.err != nil {
+		return defaultValue
+	}
+	return this.value
+}
+
+// Unwrap Return the value of this result and panic if it is failed
+func (this Result[T]) Unwrap() T {
+	if this.err != nil {
+		panic(this.err)
+	}
+	return this.value
+}
+
+//endregion
+
+//region Option
+
+// Option a value that may or may not be present
+type Option[T any] struct {
+	value   T
+	present bool
+}
+
+func Some[T any](value T) Option[T] { return Option[T]{value: value, present: true} }
+func None[T any]() Option[T]        { return Option[T]{} }
+
+func (this Option[T]) IsSome() bool   { return this.present }
+func (this Option[T]) IsNone() bool   { return !this.present }
+func (this Option[T]) Get() (T, bool) { return this.value, this.present }
+func (this Option[T]) OrElse(defaultValue T) T {
+	if !this.present {
+		return defaultValue
+	}
+	return this.value
+}
+
+//endregion
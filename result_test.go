@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestMapResult(t *testing.T) {
+	r := MapResult(NewResult(strconv.Atoi("21")), func(v int) (string, error) {
+		return strconv.Itoa(v * 2), nil
+	})
+	if !r.IsOk() || r.Unwrap() != "42" {
+		t.Errorf("expected %q but received %q(%v)", "42", r.UnwrapOr(""), r.Err())
+	}
+}
+
+func TestMapResultShortCircuit(t *testing.T) {
+	called := false
+	r := MapResult(NewResult(strconv.Atoi("x")), func(v int) (int, error) {
+		called = true
+		return v, nil
+	})
+	if called {
+		t.Errorf("expected mapper not to be called for a failed result")
+	}
+	var numErr *strconv.NumError
+	if r.IsOk() || !errors.As(r.Err(), &numErr) {
+		t.Errorf("expected the original error but received %v", r.Err())
+	}
+
+	failure := errors.New("failure")
+	r = MapResult(Ok(1), func(v int) (int, error) { return v, failure })
+	if r.Err() != failure {
+		t.Errorf("expected %v but received %v", failure, r.Err())
+	}
+	if v, _ := r.Get(); v != 0 {
+		t.Errorf("expected zero value for a failed result but received %d", v)
+	}
+}
+
+func TestResultUnwrapOr(t *testing.T) {
+	if v := Fail[int](errors.New("failure")).UnwrapOr(7); v != 7 {
+		t.Errorf("expected 7 but received %d", v)
+	}
+	if v := Ok(3).UnwrapOr(7); v != 3 {
+		t.Errorf("expected 3 but received %d", v)
+	}
+}
+
+func TestResultUnwrapPanicOnError(t *testing.T) {
+	failure := errors.New("failure")
+	defer func() {
+		if r := recover(); r != failure {
+			t.Errorf("expected panic with %v but received %v", failure, r)
+		}
+	}()
+	Fail[int](failure).Unwrap()
+}
+
+func TestOption(t *testing.T) {
+	some := Some("a")
+	if v, ok := some.Get(); !ok || v != "a" || !some.IsSome() || some.IsNone() {
+		t.Errorf("expected a present value but received %q, %v", v, ok)
+	}
+	none := None[string]()
+	if _, ok := none.Get(); ok || none.IsSome() || !none.IsNone() {
+		t.Errorf("expected no value")
+	}
+	if v := none.OrElse("b"); v != "b" {
+		t.Errorf("expected %q but received %q", "b", v)
+	}
+	if v := some.OrElse("b"); v != "a" {
+		t.Errorf("expected %q but received %q", "a", v)
+	}
+}
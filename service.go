@@ -5,9 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-const ErrServiceStopped = StringError("Service is stopped")
+const (
+	ErrServiceStopped        = StringError("Service is stopped")
+	ErrServiceAlreadyRunning = StringError("Service is already running")
+)
 
 func IsServiceStoppedError(err error) bool {
 	return err == nil || errors.Is(err, ErrServiceStopped) || errors.Is(err, http.ErrServerClosed)
@@ -68,13 +74,38 @@ func CreateServiceExecuter(factory LogFactory) ServiceExecuter {
 	return loggerServiceExecuter{Factory: factory}
 }
 
+// LoggerDecorator a function that customize a logger, e.g. attach a context to it using `WithContext`
+type LoggerDecorator func(logger Logger) Logger
+
+// CreateServiceExecuterWithDecorator create a `ServiceExecuter` that apply `decorator` to logger of each service
+func CreateServiceExecuterWithDecorator(factory LogFactory, decorator LoggerDecorator) ServiceExecuter {
+	return loggerServiceExecuter{Factory: factory, Decorator: decorator}
+}
+
 type loggerServiceExecuter struct {
-	Factory LogFactory
+	Factory   LogFactory
+	Decorator LoggerDecorator
+}
+
+// serviceLoggerName name of the logger that is used for a service
+func serviceLoggerName(service Service) string { return "services/" + service.GetName() }
+
+// ServiceLogger Create a logger for `service` with the same name that service executers use for it
+func ServiceLogger(factory LogFactory, service Service) Logger {
+	return factory.CreateLogger(serviceLoggerName(service), nil, nil)
+}
+
+func (this loggerServiceExecuter) createLogger(name string) Logger {
+	logger := this.Factory.CreateLogger(name, nil, nil)
+	if this.Decorator != nil {
+		logger = this.Decorator(logger)
+	}
+	return logger
 }
 
 func (this loggerServiceExecuter) ExecuteServiceAsync(service Service, stopRequested <-chan struct{}) (serviceStopped <-chan error) {
 	var stopped chan error
-	logger := this.Factory.CreateLogger(fmt.Sprintf("services/%s", service.GetName()), nil, nil)
+	logger := this.createLogger(serviceLoggerName(service))
 	if stopRequested == nil {
 		stopped = make(chan error, 1)
 		go func() {
@@ -112,7 +143,7 @@ func (this loggerServiceExecuter) RunService(service Service, stopRequested <-ch
 	return <-this.ExecuteServiceAsync(service, stopRequested)
 }
 func (this loggerServiceExecuter) ExecuteAsyncService(service AsyncService, stopRequested <-chan struct{}) (serviceStopped <-chan error) {
-	logger := this.Factory.CreateLogger(fmt.Sprintf("asyncServices/%s", service.GetName()), nil, nil)
+	logger := this.createLogger(fmt.Sprintf("asyncServices/%s", service.GetName()))
 	logger.Verbose(10, "Starting the service")
 	svcStopped := service.Start()
 	if stopRequested == nil {
@@ -158,6 +189,50 @@ func ExecuteAsyncService(service AsyncService, stopRequested <-chan struct{}) (s
 	return GetGlobalServiceExecuter().ExecuteAsyncService(service, stopRequested)
 }
 
+// mergeStopChannels create a channel that will be closed when any of the `channels` fire.
+// Returned function must be called to release resources when merged channel is not required anymore
+func mergeStopChannels(channels ...<-chan struct{}) (<-chan struct{}, func()) {
+	if len(channels) == 0 {
+		return nil, func() {}
+	}
+	if len(channels) == 1 {
+		return channels[0], func() {}
+	}
+
+	result := make(chan struct{})
+	done := make(chan struct{})
+	closeResult := sync.Once{}
+	for i := 0; i < len(channels); i++ {
+		go func(ch <-chan struct{}) {
+			select {
+			case <-ch:
+				closeResult.Do(func() { close(result) })
+			case <-done:
+			}
+		}(channels[i])
+	}
+	return result, func() { close(done) }
+}
+
+// RunServiceMulti execute a service and wait for its completion, service will be stopped when any of the
+// `stopChannels` fire
+func RunServiceMulti(service Service, stopChannels ...<-chan struct{}) error {
+	stopRequested, release := mergeStopChannels(stopChannels...)
+	defer release()
+
+	return RunService(service, stopRequested)
+}
+
+// RunServiceFor execute a service and request its shutdown after `d`, result of the service is returned and
+// a service that stopped cleanly because of the shutdown request return nil
+func RunServiceFor(service Service, d time.Duration) error {
+	stopRequested := make(chan struct{})
+	timer := time.AfterFunc(d, func() { close(stopRequested) })
+	defer timer.Stop()
+
+	return RunService(service, stopRequested)
+}
+
 // Helper that wrap `Service` as `AsyncService`
 type serviceToAsyncService struct {
 	service Service
@@ -228,6 +303,34 @@ func (this asyncServiceFuncs) GetName() string     { return this.Name }
 func (this asyncServiceFuncs) Start() <-chan error { return this.start() }
 func (this asyncServiceFuncs) Stop()               { this.stop() }
 
+// Helper that protect a `Service` against concurrent `Run` and multiple `Shutdown` calls
+type idempotentService struct {
+	inner        Service
+	running      int32
+	shutdownOnce sync.Once
+}
+
+// IdempotentService wrap a `Service`, so its `Shutdown` only called once and a concurrent call to `Run` fail with
+// `ErrServiceAlreadyRunning`
+func IdempotentService(inner Service) Service {
+	if wrapper, ok := inner.(*idempotentService); ok {
+		return wrapper
+	}
+	return &idempotentService{inner: inner}
+}
+func (this *idempotentService) GetName() string { return this.inner.GetName() }
+func (this *idempotentService) Run() error {
+	if !atomic.CompareAndSwapInt32(&this.running, 0, 1) {
+		return ErrServiceAlreadyRunning
+	}
+	defer atomic.StoreInt32(&this.running, 0)
+
+	return this.inner.Run()
+}
+func (this *idempotentService) Shutdown() {
+	this.shutdownOnce.Do(this.inner.Shutdown)
+}
+
 // Helper that wrap a `http.Server` as `Server`
 type httpService struct {
 	Name   string
@@ -254,7 +357,16 @@ type mergedService struct {
 	Services []Service
 }
 
+// MergeServices merge multiple services into a single `Service`, `nil` services will be ignored
 func MergeServices(name string, services ...Service) Service {
+	nonNilServices := make([]Service, 0, len(services))
+	for i := 0; i < len(services); i++ {
+		if services[i] != nil {
+			nonNilServices = append(nonNilServices, services[i])
+		}
+	}
+	services = nonNilServices
+
 	if len(services) == 0 {
 		return nil
 	}
@@ -296,7 +408,16 @@ type mergedAsyncService struct {
 	AsyncServices []AsyncService
 }
 
+// MergeAsyncServices merge multiple async services into a single `AsyncService`, `nil` services will be ignored
 func MergeAsyncServices(name string, asyncServices ...AsyncService) AsyncService {
+	nonNilServices := make([]AsyncService, 0, len(asyncServices))
+	for i := 0; i < len(asyncServices); i++ {
+		if asyncServices[i] != nil {
+			nonNilServices = append(nonNilServices, asyncServices[i])
+		}
+	}
+	asyncServices = nonNilServices
+
 	if len(asyncServices) == 0 {
 		return nil
 	}
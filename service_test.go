@@ -0,0 +1,164 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+// newBlockingService create a service that run until it is shutdown
+func newBlockingService(name string) Service {
+	stop := make(chan struct{})
+	return IdempotentService(ServiceFuncs(name,
+		func() error {
+			<-stop
+			return nil
+		},
+		func() { close(stop) }))
+}
+
+// newBlockingAsyncService create an async service that run until it is stopped
+func newBlockingAsyncService(name string) AsyncService {
+	return ServiceToAsyncService(newBlockingService(name))
+}
+
+func TestMergeServicesIgnoreNil(t *testing.T) {
+	if MergeServices("empty", nil, nil) != nil {
+		t.Errorf("expected nil when all services are nil")
+	}
+
+	a := newBlockingService("a")
+	if s := MergeServices("single", nil, a, nil); s != a {
+		t.Errorf("expected the only non-nil service to be returned as-is")
+	}
+
+	b := newBlockingService("b")
+	merged := MergeServices("merged", nil, a, nil, b, nil)
+	if merged.GetName() != "merged" {
+		t.Errorf("expected %q but received %q", "merged", merged.GetName())
+	}
+	if n := len(merged.(mergedService).Services); n != 2 {
+		t.Errorf("expected 2 services but received %d", n)
+	}
+
+	stopRequested := make(chan struct{})
+	close(stopRequested)
+	if err := RunService(merged, stopRequested); err != nil {
+		t.Errorf("expected nil error but received %v", err)
+	}
+}
+
+func TestMergeAsyncServicesIgnoreNil(t *testing.T) {
+	if MergeAsyncServices("empty", nil, nil) != nil {
+		t.Errorf("expected nil when all services are nil")
+	}
+
+	a := newBlockingAsyncService("a")
+	if s := MergeAsyncServices("single", nil, a); s != a {
+		t.Errorf("expected the only non-nil service to be returned as-is")
+	}
+
+	merged := MergeAsyncServices("merged", nil, a, nil, newBlockingAsyncService("b"))
+	if n := len(merged.(mergedAsyncService).AsyncServices); n != 2 {
+		t.Errorf("expected 2 services but received %d", n)
+	}
+
+	stopped := merged.Start()
+	merged.Stop()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("expected nil error but received %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("merged service did not stop")
+	}
+}
+
+func TestRunServiceMulti(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		stopChannels := []chan struct{}{make(chan struct{}), make(chan struct{})}
+		result := make(chan error, 1)
+		go func() {
+			result <- RunServiceMulti(newBlockingService("svc"), stopChannels[0], stopChannels[1])
+		}()
+
+		close(stopChannels[i])
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Errorf("expected a clean stop but received %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("service did not stop when stop channel %d fired", i)
+		}
+	}
+}
+
+func TestRunServiceMultiWithoutStopChannel(t *testing.T) {
+	failure := StringError("failure")
+	err := RunServiceMulti(ServiceFuncs("svc", func() error { return failure }, func() {}))
+	if err != failure {
+		t.Errorf("expected %v but received %v", failure, err)
+	}
+}
+
+func TestIdempotentServiceShutdownOnce(t *testing.T) {
+	shutdowns := 0
+	svc := IdempotentService(ServiceFuncs("svc", func() error { return nil }, func() { shutdowns++ }))
+	svc.Shutdown()
+	svc.Shutdown()
+	svc.Shutdown()
+	if shutdowns != 1 {
+		t.Errorf("expected a single shutdown but received %d", shutdowns)
+	}
+	if IdempotentService(svc) != svc {
+		t.Errorf("expected an idempotent service not to be wrapped again")
+	}
+}
+
+func TestIdempotentServiceConcurrentRun(t *testing.T) {
+	started := make(chan struct{})
+	stop := make(chan struct{})
+	svc := IdempotentService(ServiceFuncs("svc",
+		func() error {
+			close(started)
+			<-stop
+			return nil
+		},
+		func() { close(stop) }))
+
+	result := make(chan error, 1)
+	go func() { result <- svc.Run() }()
+	<-started
+
+	if err := svc.Run(); err != ErrServiceAlreadyRunning {
+		t.Errorf("expected %v but received %v", ErrServiceAlreadyRunning, err)
+	}
+	svc.Shutdown()
+	if err := <-result; err != nil {
+		t.Errorf("expected nil error but received %v", err)
+	}
+}
+
+func TestRunServiceForStopAfterDuration(t *testing.T) {
+	start := time.Now()
+	if err := RunServiceFor(newBlockingService("forever"), 50*time.Millisecond); err != nil {
+		t.Errorf("expected nil error but received %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("expected service to stop after 50ms but it stopped after %v", elapsed)
+	}
+}
+
+func TestRunServiceForFinishEarly(t *testing.T) {
+	expected := StringError("done")
+	service := ServiceFuncs("early", func() error { return expected }, func() {})
+
+	start := time.Now()
+	if err := RunServiceFor(service, time.Hour); err != expected {
+		t.Errorf("expected %v but received %v", expected, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected service to finish early but it took %v", elapsed)
+	}
+}
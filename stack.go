@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+var (
+	StackHeaderColor   Color = Yellow
+	StackFunctionColor Color = Cyan
+	StackFileColor     Color = Green
+	StackLineColor     Color = Magenta
+)
+
+// RenderStack write a stack trace(output of `debug.Stack()`) to `w` and color function names,
+// file paths and line numbers of it
+func RenderStack(w io.Writer, context ColorContext, stack []byte) error {
+	if context == nil {
+		context = GetDefaultContext(w)
+	}
+
+	cw := NewColoredWriter(context, w)
+	lines := bytes.Split(stack, EOL)
+	for i := 0; i < len(lines); i++ {
+		if i != 0 {
+			if err := cw.Write(EOL); err != nil {
+				return err
+			}
+		}
+		if err := renderStackLine(cw, string(lines[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderStackLine(cw *ColoredWriter, line string) error {
+	switch {
+	case line == "":
+		return nil
+
+	case strings.HasPrefix(line, "goroutine "):
+		return cw.WriteContent(CContent(StackHeaderColor, line))
+
+	case line[0] == '\t':
+		// \t<file>:<line> +0x<offset>
+		location := line[1:]
+		suffix := ""
+		if space := strings.IndexByte(location, ' '); space != -1 {
+			location, suffix = location[:space], location[space:]
+		}
+		file, lineNumber := location, ""
+		if colon := strings.LastIndexByte(location, ':'); colon != -1 {
+			file, lineNumber = location[:colon], location[colon+1:]
+		}
+
+		content := []interface{}{"\t", CContent(StackFileColor, file)}
+		if lineNumber != "" {
+			content = append(content, ":", CContent(StackLineColor, lineNumber))
+		}
+		content = append(content, suffix)
+		return writeStackParts(cw, content...)
+
+	default:
+		// <function>(<args>) or `created by <function>`
+		if strings.HasSuffix(line, ")") {
+			if paren := strings.LastIndexByte(line, '('); paren > 0 {
+				return writeStackParts(cw, CContent(StackFunctionColor, line[:paren]), line[paren:])
+			}
+		}
+		const createdBy = "created by "
+		if strings.HasPrefix(line, createdBy) {
+			return writeStackParts(cw, createdBy, CContent(StackFunctionColor, line[len(createdBy):]))
+		}
+		return cw.WriteString(line)
+	}
+}
+
+func writeStackParts(cw *ColoredWriter, parts ...interface{}) error {
+	for i := 0; i < len(parts); i++ {
+		if s, ok := parts[i].(string); ok && s == "" {
+			continue
+		}
+		if err := cw.WriteContent(parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+const testStack = "goroutine 1 [running]:\n" +
+	"main.work(0x1)\n" +
+	"\t/src/main.go:12 +0x1d\n" +
+	"created by main.main\n" +
+	"\t/src/main.go:5 +0x25\n"
+
+func TestRenderStackMonoColor(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := RenderStack(builder, MonoColor, []byte(testStack)); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != testStack {
+		t.Errorf("expected %q but received %q", testStack, builder.String())
+	}
+
+	stack := debug.Stack()
+	builder.Reset()
+	if err := RenderStack(builder, MonoColor, stack); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != string(stack) {
+		t.Errorf("expected %q but received %q", string(stack), builder.String())
+	}
+}
+
+func TestRenderStackTTY(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := RenderStack(builder, TTY, []byte(testStack)); err != nil {
+		t.Fatal(err)
+	}
+
+	color := func(c Color, s string) string {
+		return "\033[" + c.TerminalColorName().Foreground + "m" + s + "\033[0m"
+	}
+	expected := []string{
+		color(StackHeaderColor, "goroutine 1 [running]:"),
+		color(StackFunctionColor, "main.work") + "(0x1)",
+		"\t" + color(StackFileColor, "/src/main.go") + ":" + color(StackLineColor, "12") + " +0x1d",
+		"created by " + color(StackFunctionColor, "main.main"),
+	}
+	result := builder.String()
+	for i := 0; i < len(expected); i++ {
+		if !strings.Contains(result, expected[i]+"\n") {
+			t.Errorf("expected %q in %q", expected[i], result)
+		}
+	}
+	if StripEscapes(result) != testStack {
+		t.Errorf("expected %q but received %q", testStack, StripEscapes(result))
+	}
+}
@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SupervisionStats state of a `SupervisedService`
+type SupervisionStats struct {
+	// Restarts number of times that the service restarted after a failure
+	Restarts int
+	// LastError last error that the service failed with, `nil` if it never failed
+	LastError error
+	// LastRestart time of the last restart, zero if the service never restarted
+	LastRestart time.Time
+}
+
+// SupervisedService a `Service` that restart its inner service when it fails, waiting between restarts according
+// to a `BackoffPolicy`. It stop when the inner service stop without error, the policy does not allow another
+// retry or `Shutdown` is called
+type SupervisedService struct {
+	inner  Service
+	policy BackoffPolicy
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock  sync.Mutex
+	stats SupervisionStats
+}
+
+// SuperviseService Create a `SupervisedService` that restart `inner` according to `policy`
+func SuperviseService(inner Service, policy BackoffPolicy) *SupervisedService {
+	if inner == nil {
+		panic("Invalid argument")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SupervisedService{inner: inner, policy: policy, ctx: ctx, cancel: cancel}
+}
+
+func (this *SupervisedService) GetName() string { return this.inner.GetName() }
+func (this *SupervisedService) Run() error {
+	for attempt := 0; ; attempt++ {
+		if this.ctx.Err() != nil {
+			return nil
+		}
+
+		err := getServiceResult(this.inner.Run())
+		if err == nil || this.ctx.Err() != nil {
+			return err
+		}
+
+		this.lock.Lock()
+		this.stats.LastError = err
+		this.lock.Unlock()
+
+		if !this.policy.CanRetry(attempt) {
+			return err
+		}
+		if this.policy.Wait(this.ctx, attempt) != nil {
+			// shutdown requested while we were waiting
+			return nil
+		}
+
+		this.lock.Lock()
+		this.stats.Restarts += 1
+		this.stats.LastRestart = time.Now()
+		this.lock.Unlock()
+	}
+}
+func (this *SupervisedService) Shutdown() {
+	this.cancel()
+	this.inner.Shutdown()
+}
+
+// Stats Return a snapshot of the state of this service, it is safe to call it while the service is running
+func (this *SupervisedService) Stats() SupervisionStats {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.stats
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// immediateAfter a time source that never wait
+func immediateAfter(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+// newFailingService create a service that fail `failures` times and then stop without error
+func newFailingService(failures int32) (Service, *int32) {
+	runs := new(int32)
+	return ServiceFuncs("failing",
+		func() error {
+			if n := atomic.AddInt32(runs, 1); n <= failures {
+				return fmt.Errorf("failure %d", n)
+			}
+			return nil
+		},
+		func() {}), runs
+}
+
+func TestSupervisedServiceStats(t *testing.T) {
+	inner, runs := newFailingService(3)
+	service := SuperviseService(inner, BackoffPolicy{InitialInterval: time.Millisecond, MaxRetries: -1, After: immediateAfter})
+	if stats := service.Stats(); stats.Restarts != 0 || stats.LastError != nil || !stats.LastRestart.IsZero() {
+		t.Errorf("expected empty stats before run but received %+v", stats)
+	}
+
+	start := time.Now()
+	if err := service.Run(); err != nil {
+		t.Fatalf("expected nil error but received %v", err)
+	}
+	if n := atomic.LoadInt32(runs); n != 4 {
+		t.Errorf("expected 4 runs but received %d", n)
+	}
+
+	stats := service.Stats()
+	if stats.Restarts != 3 {
+		t.Errorf("expected 3 restarts but received %d", stats.Restarts)
+	}
+	if stats.LastError == nil || stats.LastError.Error() != "failure 3" {
+		t.Errorf("expected last error to be %q but received %v", "failure 3", stats.LastError)
+	}
+	if stats.LastRestart.Before(start) {
+		t.Errorf("expected time of the last restart but received %v", stats.LastRestart)
+	}
+}
+
+func TestSupervisedServiceGiveUp(t *testing.T) {
+	inner, runs := newFailingService(100)
+	service := SuperviseService(inner, BackoffPolicy{InitialInterval: time.Millisecond, MaxRetries: 2, After: immediateAfter})
+	err := service.Run()
+	if err == nil || err.Error() != "failure 3" {
+		t.Errorf("expected %q but received %v", "failure 3", err)
+	}
+	if n := atomic.LoadInt32(runs); n != 3 {
+		t.Errorf("expected 3 runs but received %d", n)
+	}
+	if stats := service.Stats(); stats.Restarts != 2 || stats.LastError != err {
+		t.Errorf("expected 2 restarts and the last error but received %+v", stats)
+	}
+}
+
+func TestSupervisedServiceShutdownWhileWaiting(t *testing.T) {
+	inner, _ := newFailingService(100)
+	service := SuperviseService(inner, BackoffPolicy{InitialInterval: time.Hour, MaxRetries: -1})
+
+	result := make(chan error, 1)
+	go func() { result <- service.Run() }()
+
+	// stats must be readable while the service is running
+	deadline := time.Now().Add(5 * time.Second)
+	for service.Stats().LastError == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	service.Shutdown()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("expected nil error after shutdown but received %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("service did not stop after shutdown")
+	}
+	if stats := service.Stats(); stats.Restarts != 0 || stats.LastError == nil {
+		t.Errorf("expected a failure without restart but received %+v", stats)
+	}
+}
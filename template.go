@@ -1,11 +1,13 @@
 package helpers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
-	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"text/template"
 )
@@ -32,6 +34,25 @@ func SetDefaultTemplateContext(context ColorContext) {
 	defaultTemplateContext.Store(context)
 }
 
+// colorContextKey key of the color context in a `context.Context`
+type colorContextKey struct{}
+
+// ContextWithColorContext Return a copy of `ctx` that carry `cc`, so it can be passed through call layers
+func ContextWithColorContext(ctx context.Context, cc ColorContext) context.Context {
+	return context.WithValue(ctx, colorContextKey{}, cc)
+}
+
+// ColorContextFromContext Return the color context that is stored in `ctx` by `ContextWithColorContext` or
+// `GetDefaultTemplateContext` if `ctx` does not carry any color context
+func ColorContextFromContext(ctx context.Context) ColorContext {
+	if ctx != nil {
+		if cc, ok := ctx.Value(colorContextKey{}).(ColorContext); ok && cc != nil {
+			return cc
+		}
+	}
+	return GetDefaultTemplateContext()
+}
+
 type TemplateColorContext interface {
 	GetContext() ColorContext
 	GetColorMap() *ColorNameMap
@@ -43,6 +64,12 @@ type TT_JoinedScope struct {
 	Outer interface{}
 }
 
+// TT_ScopeChain a linked list of scopes, that `Value` is the innermost scope
+type TT_ScopeChain struct {
+	Value  interface{}
+	Parent *TT_ScopeChain
+}
+
 // THF_Deref dereference a pointer
 func THF_Deref(pointer interface{}) (interface{}, error) {
 	if pointer == nil {
@@ -59,6 +86,114 @@ func THF_Deref(pointer interface{}) (interface{}, error) {
 	return nil, fmt.Errorf("Expected a pointer but received %T", pointer)
 }
 
+// THF_Get get an item from a slice(by index), a map(by key) or a struct(by field name) without panicking
+func THF_Get(collection interface{}, key interface{}) (interface{}, error) {
+	if collection == nil {
+		return nil, ErrorCantDereferenceNilPointer
+	}
+
+	rv := reflect.ValueOf(collection)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, ErrorCantDereferenceNilPointer
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		kv := reflect.ValueOf(key)
+		var index int
+		switch kv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			index = int(kv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			index = int(kv.Uint())
+		default:
+			return nil, fmt.Errorf("Index of %s must be an integer but received %T", rv.Type(), key)
+		}
+		if index < 0 || index >= rv.Len() {
+			return nil, fmt.Errorf("Index %d is out of range [0, %d)", index, rv.Len())
+		}
+		return rv.Index(index).Interface(), nil
+
+	case reflect.Map:
+		kv := reflect.ValueOf(key)
+		keyType := rv.Type().Key()
+		if !kv.IsValid() {
+			kv = reflect.Zero(keyType)
+		} else if kv.Type() != keyType {
+			converted, ok := convertMapKey(kv, keyType)
+			if !ok {
+				return nil, fmt.Errorf("Key of %s must be %s but received %T", rv.Type(), keyType, key)
+			}
+			kv = converted
+		}
+		value := rv.MapIndex(kv)
+		if !value.IsValid() {
+			return nil, fmt.Errorf("Key `%v` does not exists in the map", key)
+		}
+		return value.Interface(), nil
+
+	case reflect.Struct:
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("Field name of %s must be a string but received %T", rv.Type(), key)
+		}
+		field, ok := rv.Type().FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("%s does not have a field named `%s`", rv.Type(), name)
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("Field `%s` of %s is not exported", name, rv.Type())
+		}
+		return rv.FieldByIndex(field.Index).Interface(), nil
+
+	default:
+		return nil, fmt.Errorf("Expected a slice, map or struct but received %T", collection)
+	}
+}
+
+func isSignedKind(kind reflect.Kind) bool   { return kind >= reflect.Int && kind <= reflect.Int64 }
+func isUnsignedKind(kind reflect.Kind) bool { return kind >= reflect.Uint && kind <= reflect.Uintptr }
+
+// convertMapKey convert `kv` to `keyType` if both are integers, floats or strings and the value is kept as is,
+// so an integer never match a string key and values are never truncated
+func convertMapKey(kv reflect.Value, keyType reflect.Type) (reflect.Value, bool) {
+	zero := reflect.Zero(keyType)
+	from, to := kv.Kind(), keyType.Kind()
+	switch {
+	case to == reflect.Interface:
+		if !kv.Type().Implements(keyType) {
+			return reflect.Value{}, false
+		}
+	case isSignedKind(from) && isSignedKind(to):
+		if zero.OverflowInt(kv.Int()) {
+			return reflect.Value{}, false
+		}
+	case isSignedKind(from) && isUnsignedKind(to):
+		if kv.Int() < 0 || zero.OverflowUint(uint64(kv.Int())) {
+			return reflect.Value{}, false
+		}
+	case isUnsignedKind(from) && isSignedKind(to):
+		if kv.Uint() > math.MaxInt64 || zero.OverflowInt(int64(kv.Uint())) {
+			return reflect.Value{}, false
+		}
+	case isUnsignedKind(from) && isUnsignedKind(to):
+		if zero.OverflowUint(kv.Uint()) {
+			return reflect.Value{}, false
+		}
+	case (from == reflect.Float32 || from == reflect.Float64) && (to == reflect.Float32 || to == reflect.Float64):
+		if kv.Convert(keyType).Float() != kv.Float() {
+			return reflect.Value{}, false
+		}
+	case from == reflect.String && to == reflect.String:
+	default:
+		return reflect.Value{}, false
+	}
+	return kv.Convert(keyType), true
+}
+
 // THF_MakeDict get an even number of parameters(first is key and second is value) and create a dictionary from it
 func THF_MakeDict(values ...interface{}) (map[interface{}]interface{}, error) {
 	if (len(values) & 1) == 1 {
@@ -84,6 +219,49 @@ func THF_JoinScope(outer, inner interface{}) TT_JoinedScope {
 	return TT_JoinedScope{Inner: inner, Outer: outer}
 }
 
+// THF_PushScope push `value` as the innermost scope of the `chain`, `chain` may be nil, a scope chain,
+// a joined scope or any other value that will be used as the outermost scope
+func THF_PushScope(chain interface{}, value interface{}) *TT_ScopeChain {
+	var parent *TT_ScopeChain
+	switch v := chain.(type) {
+	case nil:
+	case *TT_ScopeChain:
+		parent = v
+	case TT_JoinedScope:
+		parent = &TT_ScopeChain{Value: v.Inner, Parent: &TT_ScopeChain{Value: v.Outer}}
+	default:
+		parent = &TT_ScopeChain{Value: v}
+	}
+	return &TT_ScopeChain{Value: value, Parent: parent}
+}
+
+func lookupScope(scope interface{}, name string) (interface{}, bool) {
+	if joined, ok := scope.(TT_JoinedScope); ok {
+		if value, ok := lookupScope(joined.Inner, name); ok {
+			return value, true
+		}
+		return lookupScope(joined.Outer, name)
+	}
+
+	value, err := THF_Get(scope, name)
+	return value, err == nil
+}
+
+// THF_Lookup search the scope chain from the innermost scope outward for a field or key named `name`
+func THF_Lookup(chain interface{}, name string) (interface{}, error) {
+	scope, ok := chain.(*TT_ScopeChain)
+	if !ok {
+		scope = &TT_ScopeChain{Value: chain}
+	}
+
+	for ; scope != nil; scope = scope.Parent {
+		if value, ok := lookupScope(scope.Value, name); ok {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("`%s` does not exists in the scope chain", name)
+}
+
 // THF_Quote quote an input string, escaping '"' and '\' character
 func THF_Quote(value interface{}) (string, error) {
 	s, ok := value.(string)
@@ -127,23 +305,32 @@ func THF_Color(codeOrName interface{}) (Color, error) {
 			return nil, ErrorInvalidColorCode
 		}
 		return RGBColor(v), nil
-	case string:
-		if v == T_NoColorName {
-			return NoColor, nil
+	case int64:
+		if v < 0 || v > 0xFFFFFF {
+			return nil, ErrorInvalidColorCode
 		}
-		if v == "" {
-			return nil, fmt.Errorf(unknownColorNameFormat, v)
+		return RGBColor(uint32(v)), nil
+	case uint32:
+		if v > 0xFFFFFF {
+			return nil, ErrorInvalidColorCode
 		}
-		if v[0] == '#' {
-			if code, err := strconv.ParseUint(v[1:], 16, 24); err != nil {
-				return RGBColor(uint32(code)), nil
-			}
+		return RGBColor(v), nil
+	case uint64:
+		if v > 0xFFFFFF {
+			return nil, ErrorInvalidColorCode
 		}
-
-		if code := GetColorCodeByName(v); code != NoColorCode {
-			return code.ToColor(), nil
+		return RGBColor(uint32(v)), nil
+	case float64:
+		// JSON numbers are decoded as float64, fraction part will be truncated
+		if math.IsNaN(v) || v < 0 || v >= 0x1000000 {
+			return nil, ErrorInvalidColorCode
+		}
+		return RGBColor(uint32(v)), nil
+	case string:
+		if v == T_NoColorName {
+			return NoColor, nil
 		}
-		return nil, fmt.Errorf(unknownColorNameFormat, v)
+		return ParseColor(v)
 	case RGBCode:
 		return v.ToColor(), nil
 	default:
@@ -154,8 +341,8 @@ func THF_Color(codeOrName interface{}) (Color, error) {
 	return nil, fmt.Errorf("%T is not a color code or color name", codeOrName)
 }
 
-// THF_ColorC if `context` implemented `TemplateColorContext`, lookup `colorName` in color map of the context
-// otherwise return result of calling `THF_Color` with `colorName`
+// THF_ColorC if `context` implemented `TemplateColorContext`, parse `colorName` like `ParseColor` but resolve
+// names using color map of the context, otherwise return result of calling `THF_Color` with `colorName`
 func THF_ColorC(context interface{}, colorName string) (Color, error) {
 	if tcc, ok := context.(TemplateColorContext); ok {
 		if colorName == "" {
@@ -164,18 +351,7 @@ func THF_ColorC(context interface{}, colorName string) (Color, error) {
 		if colorName == T_NoColorName {
 			return NoColor, nil
 		}
-		if colorName[0] == '#' {
-			if code, err := strconv.ParseUint(colorName[1:], 16, 24); err == nil {
-				return RGBColor(uint32(code)), nil
-			}
-		}
-
-		code := tcc.GetColorMap().GetColorCodeByName(colorName)
-		if code != NoColorCode {
-			return RGBColor(code), nil
-		}
-
-		return nil, fmt.Errorf(unknownColorNameFormat, colorName)
+		return tcc.GetColorMap().ParseColor(colorName)
 	} else {
 		return THF_Color(colorName)
 	}
@@ -218,9 +394,12 @@ var globalFuncs = template.FuncMap{
 	"Json":         json.Marshal,
 	"Join":         strings.Join,
 	"Deref":        THF_Deref,
+	"Get":          THF_Get,
 	"Quote":        THF_Quote,
 	"QuoteAndJoin": THF_QuoteAndJoin,
 	"JoinScope":    THF_JoinScope,
+	"PushScope":    THF_PushScope,
+	"Lookup":       THF_Lookup,
 	"MakeDict":     THF_MakeDict,
 	"Color":        THF_Color,
 	"ColorC":       THF_ColorC,
@@ -228,17 +407,53 @@ var globalFuncs = template.FuncMap{
 	"WithColorC":   THF_WithColorC,
 	"CFormat":      THF_CFormat,
 	"CFormatC":     THF_CFormatC,
+	"Lighten":      THF_Lighten,
+	"Darken":       THF_Darken,
+	"Logfmt":       THF_Logfmt,
 }
 
-func GetGlobalTemplateFuncs() template.FuncMap { return globalFuncs }
+var globalFuncsLock = sync.RWMutex{}
+
+// GetGlobalTemplateFuncs Return a copy of the global functions, use `RegisterTemplateFunc` to add a function
+func GetGlobalTemplateFuncs() template.FuncMap {
+	globalFuncsLock.RLock()
+	defer globalFuncsLock.RUnlock()
+
+	result := make(template.FuncMap, len(globalFuncs))
+	for name, f := range globalFuncs {
+		result[name] = f
+	}
+	return result
+}
 func RegisterTemplateFunc(name string, f interface{}) {
 	if f == nil || name == "" {
 		panic("Invalid argument")
 	}
 
+	globalFuncsLock.Lock()
+	defer globalFuncsLock.Unlock()
 	globalFuncs[name] = f
 }
 
+// newTemplate create a new template that have all global functions
+func newTemplate(name string) *template.Template {
+	globalFuncsLock.RLock()
+	defer globalFuncsLock.RUnlock()
+
+	// `Funcs` copy the functions to the template, so we don't need the lock after this point
+	return template.New(name).Funcs(globalFuncs)
+}
+
 func ParseTemplate(name string, body string) (*template.Template, error) {
-	return template.New(name).Funcs(globalFuncs).Parse(body)
+	return newTemplate(name).Parse(body)
+}
+
+// ParseTemplateWithFuncs parse a template that have access to global functions and `extra` functions.
+// Functions in `extra` are only available to this template and override global functions with the same name
+func ParseTemplateWithFuncs(name string, body string, extra template.FuncMap) (*template.Template, error) {
+	tmpl := newTemplate(name)
+	if len(extra) != 0 {
+		tmpl = tmpl.Funcs(extra)
+	}
+	return tmpl.Parse(body)
 }
@@ -0,0 +1,308 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+func executeTemplate(t *testing.T, tmpl *template.Template, data interface{}) string {
+	t.Helper()
+	builder := &strings.Builder{}
+	if err := tmpl.Execute(builder, data); err != nil {
+		t.Fatal(err)
+	}
+	return builder.String()
+}
+
+func TestParseTemplateWithFuncs(t *testing.T) {
+	tmpl, err := ParseTemplateWithFuncs("local", `{{ shout . }}`, template.FuncMap{
+		"shout": strings.ToUpper,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := executeTemplate(t, tmpl, "hi"); s != "HI" {
+		t.Errorf("expected HI but received %q", s)
+	}
+
+	// template local functions must not leak into the globals
+	if _, ok := GetGlobalTemplateFuncs()["shout"]; ok {
+		t.Errorf("template local function leaked into global functions")
+	}
+	if _, err = ParseTemplate("global", `{{ shout . }}`); err == nil {
+		t.Errorf("expected an error for an unknown function")
+	}
+}
+
+func TestParseTemplateWithFuncsOverrideGlobals(t *testing.T) {
+	tmpl, err := ParseTemplateWithFuncs("override", `{{ Color "Red" }}`, template.FuncMap{
+		"Color": func(name string) string { return "local:" + name },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := executeTemplate(t, tmpl, nil); s != "local:Red" {
+		t.Errorf("expected local:Red but received %q", s)
+	}
+}
+
+func TestRegisterTemplateFuncConcurrently(t *testing.T) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			RegisterTemplateFunc(fmt.Sprintf("testFunc%d", i), func() int { return i })
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := ParseTemplate("t", `{{ "x" }}`); err != nil {
+				t.Error(err)
+			}
+			_ = GetGlobalTemplateFuncs()
+		}()
+	}
+	wg.Wait()
+
+	tmpl, err := ParseTemplate("t", `{{ testFunc3 }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := executeTemplate(t, tmpl, nil); s != "3" {
+		t.Errorf("expected 3 but received %q", s)
+	}
+}
+
+func TestGetGlobalTemplateFuncsReturnCopy(t *testing.T) {
+	funcs := GetGlobalTemplateFuncs()
+	funcs["testLeaked"] = func() string { return "leaked" }
+	delete(funcs, "Color")
+
+	funcs = GetGlobalTemplateFuncs()
+	if _, ok := funcs["testLeaked"]; ok {
+		t.Errorf("mutating the returned map changed the global functions")
+	}
+	if _, ok := funcs["Color"]; !ok {
+		t.Errorf("deleting from the returned map changed the global functions")
+	}
+	if _, err := ParseTemplate("t", `{{ testLeaked }}`); err == nil {
+		t.Errorf("expected an error for an unknown function")
+	}
+}
+
+func TestTHFGet(t *testing.T) {
+	type point struct {
+		X      int
+		hidden int
+	}
+
+	tests := []struct {
+		collection interface{}
+		key        interface{}
+		expected   interface{}
+		fails      bool
+	}{
+		{[]string{"a", "b"}, 1, "b", false},
+		{[]string{"a", "b"}, 2, nil, true},
+		{[]string{"a", "b"}, -1, nil, true},
+		{[]string{"a", "b"}, "0", nil, true},
+		{map[string]int{"a": 1}, "a", 1, false},
+		{map[string]int{"a": 1}, "b", nil, true},
+		{map[int]string{1: "a"}, int64(1), "a", false},
+		{map[uint8]string{44: "a"}, 300, nil, true},
+		{map[uint8]string{255: "a"}, -1, nil, true},
+		{map[interface{}]string{"a": "x"}, "a", "x", false},
+		{&point{X: 3}, "X", 3, false},
+		{point{}, "Y", nil, true},
+		{point{}, "hidden", nil, true},
+		{nil, 0, nil, true},
+		{(*point)(nil), "X", nil, true},
+		{42, 0, nil, true},
+	}
+	for i := 0; i < len(tests); i++ {
+		value, err := THF_Get(tests[i].collection, tests[i].key)
+		if tests[i].fails {
+			if err == nil {
+				t.Errorf("%d: expected an error but received %v", i, value)
+			}
+		} else if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		} else if value != tests[i].expected {
+			t.Errorf("%d: expected %v but received %v", i, tests[i].expected, value)
+		}
+	}
+}
+
+func TestTHFGetDoesNotConvertBetweenKinds(t *testing.T) {
+	// 65 must not be converted to "A" and 1.5 must not be truncated to 1
+	if value, err := THF_Get(map[string]int{"A": 1}, 65); err == nil {
+		t.Errorf("expected an error for an int key of a string map but received %v", value)
+	}
+	if value, err := THF_Get(map[int]int{1: 1}, 1.5); err == nil {
+		t.Errorf("expected an error for a float key of an int map but received %v", value)
+	}
+}
+
+func TestTHFGetInTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("get", `{{ Get . "a" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := executeTemplate(t, tmpl, map[string]int{"a": 7}); s != "7" {
+		t.Errorf("expected 7 but received %q", s)
+	}
+	if err = tmpl.Execute(&strings.Builder{}, map[string]int{}); err == nil || !strings.Contains(err.Error(), "does not exists") {
+		t.Errorf("expected a missing key error but received %v", err)
+	}
+}
+
+func TestTHFColorNumericCodes(t *testing.T) {
+	codes := []interface{}{
+		int(0x123456), uint(0x123456), int64(0x123456), uint32(0x123456), uint64(0x123456),
+		float64(0x123456), float64(0x123456) + 0.75,
+	}
+	for i := 0; i < len(codes); i++ {
+		color, err := THF_Color(codes[i])
+		if err != nil {
+			t.Errorf("%T(%v): unexpected error %v", codes[i], codes[i], err)
+		} else if !ColorsEqual(color, RGBColor(0x123456)) {
+			t.Errorf("%T(%v): expected #123456 but received %v", codes[i], codes[i], color.Code())
+		}
+	}
+
+	invalid := []interface{}{
+		int(-1), int(0x1000000), uint(0x1000000), int64(-1), int64(0x1000000), int64(math.MaxInt64),
+		uint32(0x1000000), uint64(math.MaxUint64), float64(-1), float64(0x1000000), math.NaN(),
+	}
+	for i := 0; i < len(invalid); i++ {
+		if _, err := THF_Color(invalid[i]); err != ErrorInvalidColorCode {
+			t.Errorf("%T(%v): expected %v but received %v", invalid[i], invalid[i], ErrorInvalidColorCode, err)
+		}
+	}
+}
+
+func TestTHFLookup(t *testing.T) {
+	layout := map[string]interface{}{"title": "layout", "theme": "dark"}
+	partial := struct {
+		Title string
+		Name  string
+	}{Title: "partial", Name: "list"}
+	item := map[string]interface{}{"title": "item"}
+
+	chain := THF_PushScope(THF_PushScope(THF_PushScope(nil, layout), partial), item)
+	cases := []struct {
+		name     string
+		expected interface{}
+	}{
+		{"theme", "dark"}, // defined at the outermost scope
+		{"Name", "list"},  // defined at the middle scope
+		{"title", "item"}, // innermost scope shadow outer scopes
+		{"Title", "partial"},
+	}
+	for i := 0; i < len(cases); i++ {
+		value, err := THF_Lookup(chain, cases[i].name)
+		if err != nil || value != cases[i].expected {
+			t.Errorf("%s: expected %v but received %v(%v)", cases[i].name, cases[i].expected, value, err)
+		}
+	}
+
+	if _, err := THF_Lookup(chain, "missing"); err == nil {
+		t.Errorf("expected an error for a missing name")
+	}
+	if value, err := THF_Lookup(item, "title"); err != nil || value != "item" {
+		t.Errorf("expected a plain value to be used as a single scope but received %v(%v)", value, err)
+	}
+}
+
+func TestTHFPushScopeOnJoinedScope(t *testing.T) {
+	joined := THF_JoinScope(map[string]int{"a": 1, "b": 1}, map[string]int{"b": 2})
+	chain := THF_PushScope(joined, map[string]int{"c": 3})
+	for name, expected := range map[string]int{"a": 1, "b": 2, "c": 3} {
+		if value, err := THF_Lookup(chain, name); err != nil || value != expected {
+			t.Errorf("%s: expected %d but received %v(%v)", name, expected, value, err)
+		}
+	}
+}
+
+func TestTHFLookupInTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("lookup",
+		`{{ with $c := PushScope . (Get . "item") }}{{ Lookup $c "title" }}/{{ Lookup $c "theme" }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"title": "layout",
+		"theme": "dark",
+		"item":  map[string]string{"title": "item"},
+	}
+	if s := executeTemplate(t, tmpl, data); s != "item/dark" {
+		t.Errorf("expected item/dark but received %q", s)
+	}
+}
+
+func TestTHFColorCParseColor(t *testing.T) {
+	rec := &LogRecord{
+		Level:    Error,
+		context:  TTY,
+		colorMap: NewColorNameMap(nil).AddName("brand", 0x336699).AddName("log:E", Red.Code()),
+	}
+	cases := []struct {
+		name     string
+		expected RGBCode
+	}{
+		{"#F80", 0xFF8800},
+		{"#FF8000", 0xFF8000},
+		{"rgb(1, 2, 3)", 0x010203},
+		{"brand", 0x336699},
+		{"", 0xFF0000}, // default color of the record
+	}
+	for i := 0; i < len(cases); i++ {
+		color, err := THF_ColorC(rec, cases[i].name)
+		if err != nil || color.Code() != cases[i].expected {
+			t.Errorf("%q: expected %s but received %v(%v)", cases[i].name, cases[i].expected, color, err)
+		}
+	}
+
+	// names are resolved using map of the context, not the global map
+	if _, err := THF_ColorC(rec, "Red"); err == nil {
+		t.Errorf("expected an error for a name that is not in map of the context")
+	}
+	if _, err := THF_ColorC(rec, "#12"); err != ErrorInvalidColorCode {
+		t.Errorf("expected %v but received %v", ErrorInvalidColorCode, err)
+	}
+	if color, err := THF_ColorC(rec, T_NoColorName); err != nil || color != NoColor {
+		t.Errorf("expected NoColor but received %v(%v)", color, err)
+	}
+}
+
+func TestColorContextFromContext(t *testing.T) {
+	ctx := ContextWithColorContext(context.Background(), HTML)
+	if cc := ColorContextFromContext(ctx); cc != HTML {
+		t.Errorf("expected HTML but received %s", cc.Name())
+	}
+
+	// inner contexts override the outer ones and keep the other values
+	type testKey struct{}
+	inner := ContextWithColorContext(context.WithValue(ctx, testKey{}, "value"), TTY)
+	if cc := ColorContextFromContext(inner); cc != TTY {
+		t.Errorf("expected TTY but received %s", cc.Name())
+	}
+	if inner.Value(testKey{}) != "value" {
+		t.Errorf("expected other values of the context to be kept")
+	}
+
+	if cc := ColorContextFromContext(context.Background()); cc != GetDefaultTemplateContext() {
+		t.Errorf("expected the default template context but received %s", cc.Name())
+	}
+	if cc := ColorContextFromContext(ContextWithColorContext(context.Background(), nil)); cc != GetDefaultTemplateContext() {
+		t.Errorf("expected the default template context for a nil color context but received %s", cc.Name())
+	}
+	if cc := ColorContextFromContext(nil); cc != GetDefaultTemplateContext() {
+		t.Errorf("expected the default template context for a nil context but received %s", cc.Name())
+	}
+}
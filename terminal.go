@@ -1,7 +1,11 @@
 package helpers
 
 import (
+	"io"
 	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
 
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -9,3 +13,76 @@ import (
 func IsTerminal(f *os.File) bool {
 	return terminal.IsTerminal(int(f.Fd()))
 }
+
+// IsTerminalWriter Check if `w` write to a terminal. Beside `*os.File`, writers that implement
+// `IsTerminal() bool` or `Fd() uintptr` are also detected
+func IsTerminalWriter(w io.Writer) bool {
+	switch v := w.(type) {
+	case *os.File:
+		return IsTerminal(v)
+	case interface{ IsTerminal() bool }:
+		return v.IsTerminal()
+	case interface{ Fd() uintptr }:
+		return terminal.IsTerminal(int(v.Fd()))
+	default:
+		return false
+	}
+}
+
+// FileKind Classify `f` as a terminal, a pipe or a regular file, it return all false if `f` is none of them
+// or its mode can't be read
+func FileKind(f *os.File) (isTerminal, isPipe, isRegular bool) {
+	if IsTerminal(f) {
+		return true, false, false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, false, false
+	}
+	mode := info.Mode()
+	return false, mode&os.ModeNamedPipe != 0, mode.IsRegular()
+}
+
+var colorPipes atomic.Bool
+
+// SetColorPipes Define if `GetDefaultContext` must use `TTY` for pipes, e.g. when output is piped to a pager
+func SetColorPipes(enabled bool) { colorPipes.Store(enabled) }
+
+// isColorablePipe Check if `w` is a pipe and pipes must be colored
+func isColorablePipe(w io.Writer) bool {
+	if !colorPipes.Load() {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	_, isPipe, _ := FileKind(f)
+	return isPipe
+}
+
+// GetTerminalSize return the visible dimensions of the terminal that `f` is attached to
+func GetTerminalSize(f *os.File) (width, height int, err error) {
+	return terminal.GetSize(int(f.Fd()))
+}
+
+// SupportsUnicode Check if the locale of the process use UTF-8, so output may contain unicode symbols.
+// Legacy Windows consoles are considered as non-unicode unless we are running inside Windows Terminal
+func SupportsUnicode() bool {
+	return supportsUnicode(os.Getenv, runtime.GOOS)
+}
+func supportsUnicode(getenv func(string) string, goos string) bool {
+	if goos == "windows" {
+		return getenv("WT_SESSION") != ""
+	}
+
+	// first non-empty variable define the locale
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
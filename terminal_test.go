@@ -0,0 +1,130 @@
+package helpers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSupportsUnicode(t *testing.T) {
+	cases := []struct {
+		env      map[string]string
+		goos     string
+		expected bool
+	}{
+		{map[string]string{"LANG": "en_US.UTF-8"}, "linux", true},
+		{map[string]string{"LANG": "en_US.utf8"}, "darwin", true},
+		{map[string]string{"LANG": "C"}, "linux", false},
+		{map[string]string{"LANG": "en_US.ISO-8859-1"}, "linux", false},
+		{map[string]string{}, "linux", false},
+		// LC_ALL override LC_CTYPE and LANG
+		{map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, "linux", false},
+		{map[string]string{"LC_CTYPE": "en_US.UTF-8", "LANG": "C"}, "linux", true},
+		{map[string]string{"LANG": "en_US.UTF-8"}, "windows", false},
+		{map[string]string{"WT_SESSION": "1"}, "windows", true},
+	}
+	for i := 0; i < len(cases); i++ {
+		env := cases[i].env
+		getenv := func(name string) string { return env[name] }
+		if result := supportsUnicode(getenv, cases[i].goos); result != cases[i].expected {
+			t.Errorf("%v on %s: expected %v but received %v", env, cases[i].goos, cases[i].expected, result)
+		}
+	}
+}
+
+func TestSupportsUnicodeFromEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("locale is not used on windows")
+	}
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if !SupportsUnicode() {
+		t.Errorf("expected UTF-8 locale to support unicode")
+	}
+	t.Setenv("LANG", "C")
+	if SupportsUnicode() {
+		t.Errorf("expected C locale not to support unicode")
+	}
+}
+
+type fakeTerminalWriter struct {
+	bytes.Buffer
+	isTerminal bool
+}
+
+func (this *fakeTerminalWriter) IsTerminal() bool { return this.isTerminal }
+
+func TestIsTerminalWriter(t *testing.T) {
+	if !IsTerminalWriter(&fakeTerminalWriter{isTerminal: true}) {
+		t.Error("expected writer that report a terminal to be a terminal")
+	}
+	if IsTerminalWriter(&fakeTerminalWriter{isTerminal: false}) {
+		t.Error("expected writer that report no terminal not to be a terminal")
+	}
+	if IsTerminalWriter(&bytes.Buffer{}) {
+		t.Error("expected a buffer not to be a terminal")
+	}
+}
+
+func TestGetDefaultContextOfTerminalWriter(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "truecolor")
+	if ctx := GetDefaultContext(&fakeTerminalWriter{isTerminal: true}); ctx != TTY {
+		t.Errorf("expected TTY context but received %T", ctx)
+	}
+	if ctx := GetDefaultContext(&fakeTerminalWriter{isTerminal: false}); ctx != MonoColor {
+		t.Errorf("expected MonoColor context but received %T", ctx)
+	}
+}
+
+func TestFileKind(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if isTerminal, isPipe, isRegular := FileKind(w); isTerminal || !isPipe || isRegular {
+		t.Errorf("expected a pipe but received %v, %v, %v", isTerminal, isPipe, isRegular)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isTerminal, isPipe, isRegular := FileKind(f); isTerminal || isPipe || !isRegular {
+		t.Errorf("expected a regular file but received %v, %v, %v", isTerminal, isPipe, isRegular)
+	}
+
+	// mode of a closed file can't be read
+	f.Close()
+	if isTerminal, isPipe, isRegular := FileKind(f); isTerminal || isPipe || isRegular {
+		t.Errorf("expected a closed file to be unknown but received %v, %v, %v", isTerminal, isPipe, isRegular)
+	}
+}
+
+func TestGetDefaultContextOfPipe(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "truecolor")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if ctx := GetDefaultContext(w); ctx != MonoColor {
+		t.Errorf("expected MonoColor context for a pipe but received %T", ctx)
+	}
+
+	SetColorPipes(true)
+	defer SetColorPipes(false)
+	if ctx := GetDefaultContext(w); ctx != TTY {
+		t.Errorf("expected TTY context for a colorable pipe but received %T", ctx)
+	}
+}
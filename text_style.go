@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"io"
+	"strings"
+)
+
+// TextStyle text attributes that can be used along with a `Color`, attributes may be combined using `|`
+type TextStyle uint8
+
+const (
+	NoStyle   TextStyle = 0
+	Bold      TextStyle = 1 << 0
+	Italic    TextStyle = 1 << 1
+	Underline TextStyle = 1 << 2
+	Strike    TextStyle = 1 << 3
+)
+
+// Has Check if this style contains all attributes of `style`
+func (this TextStyle) Has(style TextStyle) bool { return this&style == style }
+
+// String Return names of the attributes of this style joined by `|`, e.g. `Bold|Underline`
+func (this TextStyle) String() string {
+	if this == NoStyle {
+		return "NoStyle"
+	}
+
+	names := []string{}
+	if this.Has(Bold) {
+		names = append(names, "Bold")
+	}
+	if this.Has(Italic) {
+		names = append(names, "Italic")
+	}
+	if this.Has(Underline) {
+		names = append(names, "Underline")
+	}
+	if this.Has(Strike) {
+		names = append(names, "Strike")
+	}
+	return strings.Join(names, "|")
+}
+
+// TerminalStyleName Return SGR parameters of this style, e.g. `1;4` for bold and underline
+func (this TextStyle) TerminalStyleName() string {
+	codes := []string{}
+	if this.Has(Bold) {
+		codes = append(codes, "1")
+	}
+	if this.Has(Italic) {
+		codes = append(codes, "3")
+	}
+	if this.Has(Underline) {
+		codes = append(codes, "4")
+	}
+	if this.Has(Strike) {
+		codes = append(codes, "9")
+	}
+	return strings.Join(codes, ";")
+}
+
+// HtmlStyleName Return CSS declarations of this style, e.g. `font-weight: bold;`
+func (this TextStyle) HtmlStyleName() string {
+	result := ""
+	if this.Has(Bold) {
+		result += "font-weight: bold;"
+	}
+	if this.Has(Italic) {
+		result += "font-style: italic;"
+	}
+	if this.Has(Underline) && this.Has(Strike) {
+		result += "text-decoration: underline line-through;"
+	} else if this.Has(Underline) {
+		result += "text-decoration: underline;"
+	} else if this.Has(Strike) {
+		result += "text-decoration: line-through;"
+	}
+	return result
+}
+
+// writeTerminalStyle write SGR sequence of `style` to `w`, nothing is written for `NoStyle`
+func writeTerminalStyle(w io.Writer, style TextStyle) error {
+	if style == NoStyle {
+		return nil
+	}
+	return writeTerminalColor(w, style.TerminalStyleName())
+}
+
+// CContentStyled Same as `CContent` but also apply `style` to the content
+func CContentStyled(color Color, style TextStyle, content interface{}) ColoredValue {
+	return CContent(color, content).WithStyle(style)
+}
+
+// CFormatStyled Same as `CFormat` but also apply `style` to the formatted content
+func CFormatStyled(color Color, style TextStyle, format string, args ...interface{}) ColoredValue {
+	return CFormat(color, format, args...).WithStyle(style)
+}
@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func cwriteString(t *testing.T, content ColoredContent, ctx ColorContext) string {
+	t.Helper()
+	builder := &strings.Builder{}
+	if err := CWrite(builder, content, ctx); err != nil {
+		t.Fatal(err)
+	}
+	return builder.String()
+}
+
+func TestTextStyleNames(t *testing.T) {
+	cases := []struct {
+		style    TextStyle
+		name     string
+		terminal string
+		html     string
+	}{
+		{NoStyle, "NoStyle", "", ""},
+		{Bold, "Bold", "1", "font-weight: bold;"},
+		{Italic, "Italic", "3", "font-style: italic;"},
+		{Underline, "Underline", "4", "text-decoration: underline;"},
+		{Strike, "Strike", "9", "text-decoration: line-through;"},
+		{Underline | Strike, "Underline|Strike", "4;9", "text-decoration: underline line-through;"},
+		{Bold | Italic | Underline | Strike, "Bold|Italic|Underline|Strike", "1;3;4;9",
+			"font-weight: bold;font-style: italic;text-decoration: underline line-through;"},
+	}
+	for i := 0; i < len(cases); i++ {
+		style := cases[i].style
+		if name := style.String(); name != cases[i].name {
+			t.Errorf("expected %q but received %q", cases[i].name, name)
+		}
+		if name := style.TerminalStyleName(); name != cases[i].terminal {
+			t.Errorf("%v: expected SGR %q but received %q", style, cases[i].terminal, name)
+		}
+		if name := style.HtmlStyleName(); name != cases[i].html {
+			t.Errorf("%v: expected CSS %q but received %q", style, cases[i].html, name)
+		}
+	}
+
+	if style := Bold | Underline; !style.Has(Bold) || !style.Has(Bold|Underline) || style.Has(Bold|Italic) {
+		t.Errorf("unexpected result of Has for %v", style)
+	}
+}
+
+func TestTextStyleTTY(t *testing.T) {
+	cases := []struct {
+		content  ColoredContent
+		expected string
+	}{
+		{CContentStyled(NoColor, Bold, "a"), "\033[1ma\033[0m"},
+		{CContentStyled(NoColor, Italic, "a"), "\033[3ma\033[0m"},
+		{CContentStyled(NoColor, Underline, "a"), "\033[4ma\033[0m"},
+		{CContentStyled(NoColor, Strike, "a"), "\033[9ma\033[0m"},
+		{CContentStyled(NoColor, Bold|Italic|Underline|Strike, "a"), "\033[1;3;4;9ma\033[0m"},
+		{CContentStyled(Red, Bold, "a"), "\033[38;2;255;0;0m\033[1ma\033[0m"},
+		{CContentStyled(NoColor, NoStyle, "a"), "a"},
+	}
+	for i := 0; i < len(cases); i++ {
+		if s := cwriteString(t, cases[i].content, TTY); s != cases[i].expected {
+			t.Errorf("%d: expected %q but received %q", i, cases[i].expected, s)
+		}
+	}
+
+	// every line is styled and reset independently in a LineTTY context
+	expected := "\033[4ma\033[0m\n\033[4mb\033[0m"
+	if s := cwriteString(t, CContentStyled(NoColor, Underline, "a\nb"), LineTTY); s != expected {
+		t.Errorf("expected %q but received %q", expected, s)
+	}
+}
+
+func TestTextStyleHTML(t *testing.T) {
+	cases := []struct {
+		content  ColoredContent
+		expected string
+	}{
+		{CContentStyled(NoColor, Bold, "a"), `<span style="font-weight: bold;">a</span>`},
+		{CContentStyled(NoColor, Italic, "a"), `<span style="font-style: italic;">a</span>`},
+		{CContentStyled(NoColor, Underline, "a"), `<span style="text-decoration: underline;">a</span>`},
+		{CContentStyled(NoColor, Strike, "a"), `<span style="text-decoration: line-through;">a</span>`},
+		{CContentStyled(NoColor, Underline|Strike, "a"),
+			`<span style="text-decoration: underline line-through;">a</span>`},
+		{CContentStyled(RGBColor(0x123456), Bold|Italic, "a"),
+			`<span style="color: #123456;font-weight: bold;font-style: italic;">a</span>`},
+		{CContentStyled(NoColor, NoStyle, "a"), "a"},
+	}
+	for i := 0; i < len(cases); i++ {
+		if s := cwriteString(t, cases[i].content, HTML); s != cases[i].expected {
+			t.Errorf("%d: expected %q but received %q", i, cases[i].expected, s)
+		}
+	}
+}
+
+func TestTextStyleNesting(t *testing.T) {
+	// inner style is combined with the outer one and the outer style is restored after it
+	content := CFormatStyled(NoColor, Bold, "x%vz", CContentStyled(NoColor, Underline, "y"))
+	expected := "\033[1mx\033[0m\033[1;4my\033[0m\033[1mz\033[0m"
+	if s := cwriteString(t, content, TTY); s != expected {
+		t.Errorf("expected %q but received %q", expected, s)
+	}
+
+	expected = `<span style="font-weight: bold;">x</span>` +
+		`<span style="font-weight: bold;text-decoration: underline;">y</span>` +
+		`<span style="font-weight: bold;">z</span>`
+	if s := cwriteString(t, content, HTML); s != expected {
+		t.Errorf("expected %q but received %q", expected, s)
+	}
+
+	// style of a value does not leak to the content that written after it
+	content = CFormat(NoColor, "%v%v", CContentStyled(NoColor, Italic, "a"), "b")
+	if s := cwriteString(t, content, TTY); s != "\033[3ma\033[0mb" {
+		t.Errorf("expected style to be restored but received %q", s)
+	}
+
+	if value := CContentStyled(Red, Bold, "a").WithStyle(Italic); value.Style != Bold|Italic {
+		t.Errorf("expected WithStyle to combine styles but received %v", value.Style)
+	}
+}
+
+func TestTextStyleWithoutColors(t *testing.T) {
+	content := CFormatStyled(Red, Bold|Strike, "x%vz", CContentStyled(Blue, Underline, "y"))
+	contexts := []ColorContext{MonoColor, Strip}
+	for i := 0; i < len(contexts); i++ {
+		if s := cwriteString(t, content, contexts[i]); s != "xyz" {
+			t.Errorf("%s: expected no style codes but received %q", contexts[i].Name(), s)
+		}
+	}
+}
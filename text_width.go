@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRuneRanges ranges of runes that occupy two cells in a terminal(East Asian Wide and Fullwidth)
+var wideRuneRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x231A, 0x231B},   // watch, hourglass
+	{0x2329, 0x232A},   // angle brackets
+	{0x2E80, 0x303E},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x1F300, 0x1F64F}, // pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // supplemental symbols and pictographs
+	{0x20000, 0x2FFFD}, // CJK unified ideographs extension B..F
+	{0x30000, 0x3FFFD}, // CJK unified ideographs extension G
+}
+
+// RuneWidth Number of terminal cells that `r` occupy, 0 for control and combining characters
+func RuneWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	if r < wideRuneRanges[0][0] {
+		return 1
+	}
+
+	low, high := 0, len(wideRuneRanges)-1
+	for low <= high {
+		mid := (low + high) / 2
+		if r < wideRuneRanges[mid][0] {
+			high = mid - 1
+		} else if r > wideRuneRanges[mid][1] {
+			low = mid + 1
+		} else {
+			return 2
+		}
+	}
+	return 1
+}
+
+// skipEscapeSequence return length of the escape sequence at the start of `s`(that must start with ESC)
+func skipEscapeSequence(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	if s[1] != '[' {
+		// a two character escape sequence
+		return 2
+	}
+
+	// CSI: ESC [ parameters intermediates final(0x40-0x7E)
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7E {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// StripEscapes Remove all terminal escape sequences from `s`
+func StripEscapes(s string) string {
+	result := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			i += skipEscapeSequence(s[i:])
+		} else {
+			result = append(result, s[i])
+			i++
+		}
+	}
+	return string(result)
+}
+
+// VisibleWidth Number of terminal cells that is required to display `s`, escape sequences are ignored
+func VisibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			i += skipEscapeSequence(s[i:])
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += RuneWidth(r)
+		i += size
+	}
+	return width
+}
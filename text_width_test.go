@@ -0,0 +1,41 @@
+package helpers
+
+import "testing"
+
+func TestVisibleWidth(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"\033[38;2;255;0;0mhello\033[0m", 5},
+		{"a\033[1mb\033[22mc", 3},
+		{"日本語", 6},
+		{"\033[31m日本\033[0m!", 5},
+		{"한글", 4},
+		{"é", 1},
+		{"a\tb", 2},
+	}
+	for i := 0; i < len(cases); i++ {
+		if w := VisibleWidth(cases[i].s); w != cases[i].width {
+			t.Errorf("VisibleWidth(%q): expected %d but received %d", cases[i].s, cases[i].width, w)
+		}
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	cases := map[rune]int{'a': 1, '日': 2, '\u0301': 0, '\n': 0, 'Ａ': 2, '😀': 2, 'é': 1}
+	for r, width := range cases {
+		if w := RuneWidth(r); w != width {
+			t.Errorf("RuneWidth(%q): expected %d but received %d", r, width, w)
+		}
+	}
+}
+
+func TestStripEscapes(t *testing.T) {
+	s := StripEscapes("\033[38;2;255;0;0mred\033[0m and \033[1;4mbold\033[m")
+	if s != "red and bold" {
+		t.Errorf("expected %q but received %q", "red and bold", s)
+	}
+}
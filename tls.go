@@ -11,15 +11,19 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"io"
 	"io/ioutil"
 	"math"
 	"math/big"
+	"strings"
 	"time"
 )
 
 var (
 	UnsupportedEncryptionType = errors.New("Unsupported encryption type")
 	NoIssuerCertMustBeCA      = errors.New("When there is no issuer for the certificate it must be a CA")
+	ErrNoCrossSignIssuer      = errors.New("Cross signing require at least one non-nil issuer")
+	ErrNoCertificateValidity  = errors.New("Either NotAfter or ValidFor of the certificate must be set")
 
 	ErrInvalidPEMFile      = errors.New("Invalid PEM file")
 	ErrNoCertificate       = errors.New("PEM file does not contains any certificate")
@@ -38,10 +42,35 @@ const (
 	ECDSA224 CryptoAlgorithm = "ECDSA224"
 	ECDSA256 CryptoAlgorithm = "ECDSA256"
 	ECDSA384 CryptoAlgorithm = "ECDSA384"
+	// ECDSA521 value of this algorithm is kept as `ECDSAP521` for compatibility, `ParseCryptoAlgorithm` accept
+	// both `ECDSA521` and `ECDSAP521`
 	ECDSA521 CryptoAlgorithm = "ECDSAP521"
 	ED25519  CryptoAlgorithm = "ED25519"
 )
 
+var allCryptoAlgorithms = []CryptoAlgorithm{RSA2048, RSA4096, RSA8192, ECDSA224, ECDSA256, ECDSA384, ECDSA521, ED25519}
+
+// AllCryptoAlgorithms Return all supported crypto algorithms
+func AllCryptoAlgorithms() []CryptoAlgorithm {
+	result := make([]CryptoAlgorithm, len(allCryptoAlgorithms))
+	copy(result, allCryptoAlgorithms)
+	return result
+}
+
+// ParseCryptoAlgorithm Parse name of a crypto algorithm(case insensitive)
+func ParseCryptoAlgorithm(s string) (CryptoAlgorithm, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if name == "ECDSA521" {
+		return ECDSA521, nil
+	}
+	for i := 0; i < len(allCryptoAlgorithms); i++ {
+		if string(allCryptoAlgorithms[i]) == name {
+			return allCryptoAlgorithms[i], nil
+		}
+	}
+	return "", UnsupportedEncryptionType
+}
+
 func bigMul(values ...int64) *big.Int {
 	z := big.NewInt(1)
 	for i := 0; i < len(values); i++ {
@@ -92,24 +121,69 @@ func GetPublicKey(priv crypto.PrivateKey) (crypto.PublicKey, error) {
 	}
 }
 
+// X509CertificateOptions options that used to create a `x509.Certificate`
+type X509CertificateOptions struct {
+	// Subject of the certificate
+	Subject pkix.Name
+	// SerialNumber serial number of the certificate, a random serial will be used if it is `nil`
+	SerialNumber *big.Int
+	IsCA         bool
+	// NotBefore start of validity of the certificate, 5 minutes ago if it is not set
+	NotBefore time.Time
+	// NotAfter end of validity of the certificate, if it is not set `ValidFor` will be used to calculate it
+	NotAfter time.Time
+	// ValidFor validity duration of the certificate from now, it is only used if `NotAfter` is not set.
+	// Creating a certificate fail with `ErrNoCertificateValidity` if neither of them is set
+	ValidFor time.Duration
+}
+
 func CreateX509Certificate(commonName string, isCA bool, expiryTime time.Time) (*x509.Certificate, error) {
-	serialNumber, err := rand.Int(rand.Reader, maxSerialNumber)
-	if err != nil {
-		return nil, err
+	return CreateX509CertificateWithOptions(X509CertificateOptions{
+		Subject:  pkix.Name{CommonName: commonName},
+		IsCA:     isCA,
+		NotAfter: expiryTime,
+	})
+}
+func CreateX509CertificateValidFor(commonName string, isCA bool, validFor time.Duration) (*x509.Certificate, error) {
+	return CreateX509CertificateWithOptions(X509CertificateOptions{
+		Subject:  pkix.Name{CommonName: commonName},
+		IsCA:     isCA,
+		ValidFor: validFor,
+	})
+}
+func CreateX509CertificateWithOptions(options X509CertificateOptions) (*x509.Certificate, error) {
+	serialNumber := options.SerialNumber
+	if serialNumber == nil {
+		var err error
+		serialNumber, err = rand.Int(rand.Reader, maxSerialNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	notBefore := options.NotBefore
+	if notBefore.IsZero() {
+		notBefore = now.Add(-5 * time.Minute)
+	}
+	notAfter := options.NotAfter
+	if notAfter.IsZero() {
+		if options.ValidFor == 0 {
+			return nil, ErrNoCertificateValidity
+		}
+		notAfter = now.Add(options.ValidFor)
 	}
 
 	result := &x509.Certificate{
-		IsCA: isCA,
-		Subject: pkix.Name{
-			CommonName: commonName,
-		},
+		IsCA:         options.IsCA,
+		Subject:      options.Subject,
 		SerialNumber: serialNumber,
-		NotBefore:    time.Now().Add(-5 * time.Minute),
-		NotAfter:     expiryTime,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
 		KeyUsage:     x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 	}
-	if isCA {
+	if options.IsCA {
 		result.BasicConstraintsValid = true
 		result.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment
 	}
@@ -170,6 +244,36 @@ func loadPEMBuffer(buffer []byte) (*x509.Certificate, crypto.PrivateKey, error)
 	}
 	return cert, key, err
 }
+
+// SplitPEM separate certificates and private key of a PEM buffer, all certificates(e.g. a chain) are kept in
+// `certPEM` in their original order
+func SplitPEM(buffer []byte) (certPEM, keyPEM []byte, err error) {
+	var block *pem.Block
+	found := false
+	block, buffer = pem.Decode(buffer)
+	for block != nil {
+		found = true
+		switch block.Type {
+		case "CERTIFICATE":
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+
+		case "PRIVATE KEY", "EC PRIVATE KEY", "RSA PRIVATE KEY":
+			if keyPEM != nil {
+				return nil, nil, ErrMultipleKey
+			}
+			keyPEM = pem.EncodeToMemory(block)
+
+		default:
+			// ignore other kind of blocks
+		}
+
+		block, buffer = pem.Decode(buffer)
+	}
+	if !found {
+		return nil, nil, ErrInvalidPEMFile
+	}
+	return certPEM, keyPEM, nil
+}
 func loadPEM(file string) (*x509.Certificate, crypto.PrivateKey, error) {
 	buffer, err := ioutil.ReadFile(file)
 	if err != nil {
@@ -178,8 +282,7 @@ func loadPEM(file string) (*x509.Certificate, crypto.PrivateKey, error) {
 
 	return loadPEMBuffer(buffer)
 }
-func LoadCertAndKeyFromFile(file string) (*CertAndKey, error) {
-	cert, key, err := loadPEM(file)
+func newCertAndKey(cert *x509.Certificate, key crypto.PrivateKey, err error) (*CertAndKey, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +296,23 @@ func LoadCertAndKeyFromFile(file string) (*CertAndKey, error) {
 
 	return &CertAndKey{Certificate: cert, PrivateKey: key}, nil
 }
+func LoadCertAndKeyFromFile(file string) (*CertAndKey, error) {
+	return newCertAndKey(loadPEM(file))
+}
+
+// LoadCertAndKeyFromBytes Load a certificate and its private key from a PEM bundle
+func LoadCertAndKeyFromBytes(buffer []byte) (*CertAndKey, error) {
+	return newCertAndKey(loadPEMBuffer(buffer))
+}
+
+// LoadCertAndKeyFromReader Read a PEM bundle from `r` and load the certificate and its private key from it
+func LoadCertAndKeyFromReader(r io.Reader) (*CertAndKey, error) {
+	buffer, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCertAndKeyFromBytes(buffer)
+}
 func LoadCertAndKeyFromCertAndKey(certFile, keyFile string) (*CertAndKey, error) {
 	cert, _, err := loadPEM(certFile)
 	if err != nil {
@@ -251,6 +371,36 @@ func CreateCertificate(cert *x509.Certificate, privateKey crypto.PrivateKey, iss
 		PrivateKey:  privateKey,
 	}, nil
 }
+
+// CrossSign Sign `cert` with each of the `issuers`, all created certificates share `key`. If `key` is nil
+// a new key will be generated and shared between them
+func CrossSign(cert *x509.Certificate, key crypto.PrivateKey, issuers ...*CertAndKey) ([]*CertAndKey, error) {
+	if len(issuers) == 0 {
+		return nil, ErrNoCrossSignIssuer
+	}
+
+	if key == nil {
+		var err error
+		key, err = CreatePrivateKey(RSA4096)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*CertAndKey, len(issuers))
+	for i := 0; i < len(issuers); i++ {
+		if issuers[i] == nil {
+			return nil, ErrNoCrossSignIssuer
+		}
+
+		signed, err := CreateCertificate(cert, key, issuers[i])
+		if err != nil {
+			return nil, err
+		}
+		result[i] = signed
+	}
+	return result, nil
+}
 func (this *CertAndKey) CertificatePEMBlock() (*pem.Block, error) {
 	if this.Certificate.Raw == nil {
 		return nil, errors.New("Certificate missing DER information")
@@ -0,0 +1,311 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// newTestCertificate create a certificate with a fast ECDSA key that is signed by `issuer` or self signed
+func newTestCertificate(t *testing.T, commonName string, isCA bool, issuer *CertAndKey) *CertAndKey {
+	t.Helper()
+	cert, err := CreateX509CertificateValidFor(commonName, isCA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := CreatePrivateKey(ECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := CreateCertificate(cert, key, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func encodeTestPEM(t *testing.T, certs []*CertAndKey, key *CertAndKey) []byte {
+	t.Helper()
+	result := []byte{}
+	for i := 0; i < len(certs); i++ {
+		block, err := certs[i].CertificatePEMBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, pem.EncodeToMemory(block)...)
+	}
+	if key != nil {
+		block, err := key.PrivateKeyPEMBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, pem.EncodeToMemory(block)...)
+	}
+	return result
+}
+
+func TestCreateX509CertificateWithSubjectAndSerial(t *testing.T) {
+	cert, err := CreateX509CertificateWithOptions(X509CertificateOptions{
+		Subject: pkix.Name{
+			CommonName:         "test",
+			Organization:       []string{"Org"},
+			OrganizationalUnit: []string{"Unit"},
+			Country:            []string{"IR"},
+		},
+		SerialNumber: big.NewInt(12345),
+		IsCA:         true,
+		ValidFor:     time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := CreatePrivateKey(ECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := CreateCertificate(cert, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := created.Certificate
+	if parsed.SerialNumber.Cmp(big.NewInt(12345)) != 0 {
+		t.Errorf("expected serial 12345 but received %v", parsed.SerialNumber)
+	}
+	subject := parsed.Subject
+	if subject.CommonName != "test" {
+		t.Errorf("expected %q but received %q", "test", subject.CommonName)
+	}
+	if len(subject.Organization) != 1 || subject.Organization[0] != "Org" {
+		t.Errorf("expected [Org] but received %v", subject.Organization)
+	}
+	if len(subject.OrganizationalUnit) != 1 || subject.OrganizationalUnit[0] != "Unit" {
+		t.Errorf("expected [Unit] but received %v", subject.OrganizationalUnit)
+	}
+	if len(subject.Country) != 1 || subject.Country[0] != "IR" {
+		t.Errorf("expected [IR] but received %v", subject.Country)
+	}
+}
+
+func TestCreateX509CertificateRandomSerial(t *testing.T) {
+	a, err := CreateX509CertificateValidFor("a", true, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CreateX509CertificateValidFor("b", true, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SerialNumber.Cmp(b.SerialNumber) == 0 {
+		t.Errorf("expected random serials to be different")
+	}
+}
+
+func TestCreateX509CertificateValidity(t *testing.T) {
+	before := time.Now()
+	cert, err := CreateX509CertificateValidFor("test", true, 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+	if cert.NotAfter.Before(before.Add(90*24*time.Hour)) || cert.NotAfter.After(after.Add(90*24*time.Hour)) {
+		t.Errorf("expected NotAfter to be 90 days from now but received %v", cert.NotAfter)
+	}
+	if cert.NotBefore.Before(before.Add(-5*time.Minute)) || cert.NotBefore.After(after.Add(-5*time.Minute)) {
+		t.Errorf("expected NotBefore to be 5 minutes ago but received %v", cert.NotBefore)
+	}
+
+	notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert, err = CreateX509CertificateWithOptions(X509CertificateOptions{
+		Subject:   pkix.Name{CommonName: "test"},
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+		ValidFor:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.NotBefore.Equal(notBefore) || !cert.NotAfter.Equal(notAfter) {
+		t.Errorf("expected [%v, %v] but received [%v, %v]", notBefore, notAfter, cert.NotBefore, cert.NotAfter)
+	}
+}
+
+func TestCreateX509CertificateWithoutValidity(t *testing.T) {
+	if _, err := CreateX509CertificateWithOptions(X509CertificateOptions{IsCA: true}); err != ErrNoCertificateValidity {
+		t.Errorf("expected %v but received %v", ErrNoCertificateValidity, err)
+	}
+	if _, err := CreateX509Certificate("test", true, time.Time{}); err != ErrNoCertificateValidity {
+		t.Errorf("expected %v but received %v", ErrNoCertificateValidity, err)
+	}
+}
+
+func TestSplitPEM(t *testing.T) {
+	root := newTestCertificate(t, "root", true, nil)
+	intermediate := newTestCertificate(t, "intermediate", true, root)
+	leaf := newTestCertificate(t, "leaf", false, intermediate)
+
+	// key is placed between the certificates to make sure order of certificates is kept
+	leafPEM := encodeTestPEM(t, []*CertAndKey{leaf}, nil)
+	intermediatePEM := encodeTestPEM(t, []*CertAndKey{intermediate}, nil)
+	keyPEM := encodeTestPEM(t, nil, leaf)
+	combined := append(append(append([]byte{}, leafPEM...), keyPEM...), intermediatePEM...)
+
+	certs, key, err := SplitPEM(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(certs, append(append([]byte{}, leafPEM...), intermediatePEM...)) {
+		t.Errorf("expected leaf and intermediate certificates but received %q", certs)
+	}
+	if !bytes.Equal(key, keyPEM) {
+		t.Errorf("expected %q but received %q", keyPEM, key)
+	}
+
+	block, rest := pem.Decode(certs)
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || parsed.Subject.CommonName != "leaf" {
+		t.Errorf("expected leaf certificate to be first but received %v(%v)", parsed, err)
+	}
+	block, _ = pem.Decode(rest)
+	parsed, err = x509.ParseCertificate(block.Bytes)
+	if err != nil || parsed.Subject.CommonName != "intermediate" {
+		t.Errorf("expected intermediate certificate to be second but received %v(%v)", parsed, err)
+	}
+}
+
+func TestSplitPEMErrors(t *testing.T) {
+	cert := newTestCertificate(t, "root", true, nil)
+	keyPEM := encodeTestPEM(t, nil, cert)
+	if _, _, err := SplitPEM(append(append([]byte{}, keyPEM...), keyPEM...)); err != ErrMultipleKey {
+		t.Errorf("expected %v but received %v", ErrMultipleKey, err)
+	}
+	if _, _, err := SplitPEM([]byte("not a PEM")); err != ErrInvalidPEMFile {
+		t.Errorf("expected %v but received %v", ErrInvalidPEMFile, err)
+	}
+
+	certs, key, err := SplitPEM(encodeTestPEM(t, []*CertAndKey{cert}, nil))
+	if err != nil || len(certs) == 0 || key != nil {
+		t.Errorf("expected only certificates but received %q, %q, %v", certs, key, err)
+	}
+}
+
+func TestCrossSign(t *testing.T) {
+	root1 := newTestCertificate(t, "root1", true, nil)
+	root2 := newTestCertificate(t, "root2", true, nil)
+	cert, err := CreateX509CertificateValidFor("leaf", false, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := CreatePrivateKey(ECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := CrossSign(cert, key, root1, root2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != 2 {
+		t.Fatalf("expected 2 certificates but received %d", len(signed))
+	}
+
+	issuers := []*CertAndKey{root1, root2}
+	for i := 0; i < len(signed); i++ {
+		if signed[i].PrivateKey != key {
+			t.Errorf("certificate %d: expected the shared key", i)
+		}
+		if signed[i].Certificate.Issuer.CommonName != issuers[i].Certificate.Subject.CommonName {
+			t.Errorf("certificate %d: expected issuer %q but received %q", i,
+				issuers[i].Certificate.Subject.CommonName, signed[i].Certificate.Issuer.CommonName)
+		}
+		if err = signed[i].Certificate.CheckSignatureFrom(issuers[i].Certificate); err != nil {
+			t.Errorf("certificate %d: expected to be signed by its issuer: %v", i, err)
+		}
+		if err = signed[i].Certificate.CheckSignatureFrom(issuers[1-i].Certificate); err == nil {
+			t.Errorf("certificate %d: expected not to be signed by the other issuer", i)
+		}
+	}
+}
+
+func TestCrossSignWithoutIssuer(t *testing.T) {
+	cert, err := CreateX509CertificateValidFor("leaf", false, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CrossSign(cert, nil); err != ErrNoCrossSignIssuer {
+		t.Errorf("expected %v but received %v", ErrNoCrossSignIssuer, err)
+	}
+	if _, err = CrossSign(cert, nil, nil); err != ErrNoCrossSignIssuer {
+		t.Errorf("expected %v but received %v", ErrNoCrossSignIssuer, err)
+	}
+}
+
+func TestParseCryptoAlgorithm(t *testing.T) {
+	all := AllCryptoAlgorithms()
+	if len(all) != 8 {
+		t.Errorf("expected 8 algorithms but received %v", all)
+	}
+	for i := 0; i < len(all); i++ {
+		for _, s := range []string{string(all[i]), strings.ToLower(string(all[i])), " " + string(all[i]) + " "} {
+			if algorithm, err := ParseCryptoAlgorithm(s); err != nil || algorithm != all[i] {
+				t.Errorf("%q: expected %s but received %s(%v)", s, all[i], algorithm, err)
+			}
+		}
+	}
+
+	for _, s := range []string{"ECDSA521", "ecdsap521"} {
+		if algorithm, err := ParseCryptoAlgorithm(s); err != nil || algorithm != ECDSA521 {
+			t.Errorf("%q: expected %s but received %s(%v)", s, ECDSA521, algorithm, err)
+		}
+	}
+	if _, err := ParseCryptoAlgorithm("DSA1024"); err != UnsupportedEncryptionType {
+		t.Errorf("expected %v but received %v", UnsupportedEncryptionType, err)
+	}
+
+	// result must be a copy
+	all[0] = "changed"
+	if AllCryptoAlgorithms()[0] != RSA2048 {
+		t.Errorf("expected AllCryptoAlgorithms to return a copy")
+	}
+}
+
+func TestLoadCertAndKeyFromBytes(t *testing.T) {
+	cert := newTestCertificate(t, "root", true, nil)
+	bundle := encodeTestPEM(t, []*CertAndKey{cert}, cert)
+
+	fromBytes, err := LoadCertAndKeyFromBytes(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromReader, err := LoadCertAndKeyFromReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := []*CertAndKey{fromBytes, fromReader}
+	for i := 0; i < len(loaded); i++ {
+		if !loaded[i].Certificate.Equal(cert.Certificate) {
+			t.Errorf("%d: expected the encoded certificate", i)
+		}
+		key, ok := loaded[i].PrivateKey.(interface{ Equal(crypto.PrivateKey) bool })
+		if !ok || !key.Equal(cert.PrivateKey) {
+			t.Errorf("%d: expected the encoded private key", i)
+		}
+	}
+
+	if _, err = LoadCertAndKeyFromBytes([]byte("not a PEM")); err != ErrInvalidPEMFile {
+		t.Errorf("expected %v but received %v", ErrInvalidPEMFile, err)
+	}
+	if _, err = LoadCertAndKeyFromReader(iotest.ErrReader(io.ErrUnexpectedEOF)); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v but received %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -28,3 +28,99 @@ func WildcardToRegexp(s string) string {
 	}
 	return pattern
 }
+
+// matchWildcardRunes match `s` against a wildcard `pattern` without using regular expressions
+func matchWildcardRunes(pattern, s []rune) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+	for i < len(s) {
+		if p < len(pattern) && (pattern[p] == '?' || (pattern[p] != '*' && pattern[p] == s[i])) {
+			p++
+			i++
+		} else if p < len(pattern) && pattern[p] == '*' {
+			starP, starI = p, i
+			p++
+		} else if starP != -1 {
+			// let last `*` consume one more rune and try again
+			starI++
+			p, i = starP+1, starI
+		} else {
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// WildcardMatch Check if `s` match the wildcard `pattern`, `*` match any sequence and `?` match a single character
+func WildcardMatch(pattern, s string) bool {
+	return matchWildcardRunes([]rune(pattern), []rune(s))
+}
+
+// WildcardSet A set of wildcard patterns that may be matched against a string at once
+type WildcardSet struct {
+	literals   map[string]struct{}
+	literalsNC map[string]struct{}
+	patterns   [][]rune
+	patternsNC [][]rune
+}
+
+// NewWildcardSet Create a `WildcardSet` from a list of patterns
+func NewWildcardSet(patterns ...string) *WildcardSet {
+	result := &WildcardSet{
+		literals:   make(map[string]struct{}),
+		literalsNC: make(map[string]struct{}),
+	}
+	for i := 0; i < len(patterns); i++ {
+		result.Add(patterns[i])
+	}
+	return result
+}
+
+// Add Add a pattern to this set
+func (this *WildcardSet) Add(pattern string) *WildcardSet {
+	if IsWildcard(pattern) {
+		this.patterns = append(this.patterns, []rune(pattern))
+		this.patternsNC = append(this.patternsNC, []rune(strings.ToLower(pattern)))
+	} else {
+		this.literals[pattern] = struct{}{}
+		this.literalsNC[strings.ToLower(pattern)] = struct{}{}
+	}
+	return this
+}
+
+// Len Number of patterns in this set
+func (this *WildcardSet) Len() int { return len(this.literals) + len(this.patterns) }
+
+// MatchAny Check if `s` match any of the patterns of this set
+func (this *WildcardSet) MatchAny(s string) bool {
+	if _, ok := this.literals[s]; ok {
+		return true
+	}
+	return matchAnyWildcard(this.patterns, s)
+}
+
+// MatchAnyNC Check if `s` match any of the patterns of this set ignoring case of the values
+func (this *WildcardSet) MatchAnyNC(s string) bool {
+	s = strings.ToLower(s)
+	if _, ok := this.literalsNC[s]; ok {
+		return true
+	}
+	return matchAnyWildcard(this.patternsNC, s)
+}
+
+func matchAnyWildcard(patterns [][]rune, s string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(patterns); i++ {
+		if matchWildcardRunes(patterns[i], runes) {
+			return true
+		}
+	}
+	return false
+}
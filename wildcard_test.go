@@ -0,0 +1,78 @@
+package helpers
+
+import "testing"
+
+func TestWildcardMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"a*c", "ac", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbbd", false},
+		{"*.go", "main.go", true},
+		{"*.go", "main.goo", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxcyyb", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+	for i := 0; i < len(cases); i++ {
+		c := cases[i]
+		if WildcardMatch(c.pattern, c.s) != c.match {
+			t.Errorf("WildcardMatch(%q, %q): expected %v", c.pattern, c.s, c.match)
+		}
+	}
+}
+
+func TestWildcardSetMatchAny(t *testing.T) {
+	set := NewWildcardSet("exact", "*.go", "test-?")
+	if set.Len() != 3 {
+		t.Errorf("expected 3 patterns but received %d", set.Len())
+	}
+
+	matches := []string{"exact", "main.go", "test-1"}
+	for i := 0; i < len(matches); i++ {
+		if !set.MatchAny(matches[i]) {
+			t.Errorf("expected %q to match", matches[i])
+		}
+	}
+
+	nonMatches := []string{"exactly", "main.c", "test-12", "EXACT", "MAIN.GO"}
+	for i := 0; i < len(nonMatches); i++ {
+		if set.MatchAny(nonMatches[i]) {
+			t.Errorf("expected %q not to match", nonMatches[i])
+		}
+	}
+}
+
+func TestWildcardSetMatchAnyNC(t *testing.T) {
+	set := NewWildcardSet("Exact", "*.Go")
+	matches := []string{"exact", "EXACT", "main.go", "MAIN.GO"}
+	for i := 0; i < len(matches); i++ {
+		if !set.MatchAnyNC(matches[i]) {
+			t.Errorf("expected %q to match ignoring case", matches[i])
+		}
+	}
+	if set.MatchAnyNC("main.c") {
+		t.Errorf("expected %q not to match ignoring case", "main.c")
+	}
+}
+
+func TestWildcardSetEmpty(t *testing.T) {
+	set := NewWildcardSet()
+	if set.Len() != 0 {
+		t.Errorf("expected empty set but received %d patterns", set.Len())
+	}
+	if set.MatchAny("") || set.MatchAny("a") {
+		t.Error("expected empty set to match nothing")
+	}
+	if set.MatchAnyNC("") || set.MatchAnyNC("a") {
+		t.Error("expected empty set to match nothing ignoring case")
+	}
+}